@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/b-charles/pigs/ioc"
+)
+
+/*
+ * Aliases
+ */
+
+var declaredAliases = map[string]string{}
+
+// Alias records a deprecated key, renamed to a new key. The value of the old
+// key (and of its children, for a list or a map) is used for the new key if the
+// new key is not defined, or only defined by default.
+func Alias(oldKey, newKey string) {
+
+	if oldKey == "" || newKey == "" || oldKey == newKey {
+		panic(fmt.Sprintf("Invalid alias '%s' -> '%s'.", oldKey, newKey))
+	}
+	if old, p := declaredAliases[oldKey]; p {
+		panic(fmt.Sprintf("The key '%s' can't be aliased twice ('%s' and '%s').", oldKey, old, newKey))
+	}
+
+	declaredAliases[oldKey] = newKey
+
+}
+
+func BackupAliases() map[string]string {
+	backup := make(map[string]string, len(declaredAliases))
+	for k, v := range declaredAliases {
+		backup[k] = v
+	}
+	return backup
+}
+
+func RestoreAliases(backup map[string]string) {
+	for k := range declaredAliases {
+		delete(declaredAliases, k)
+	}
+	for k, v := range backup {
+		declaredAliases[k] = v
+	}
+}
+
+// subKey returns the key with the prefix replaced, if the key is the prefix or
+// one of its children.
+func subKey(key, from, to string) (string, bool) {
+	if key == from {
+		return to, true
+	} else if strings.HasPrefix(key, from+".") {
+		return to + key[len(from):], true
+	} else {
+		return "", false
+	}
+}
+
+// sortedAliases returns the deprecated keys of the aliases, the ones renamed to
+// the most specific new keys first, then in lexicographical order. The order is
+// computed once, when the configuration is created.
+func sortedAliases(aliases map[string]string) []string {
+
+	oldKeys := make([]string, 0, len(aliases))
+	for oldKey := range aliases {
+		oldKeys = append(oldKeys, oldKey)
+	}
+
+	sort.Slice(oldKeys, func(i, j int) bool {
+		ni, nj := aliases[oldKeys[i]], aliases[oldKeys[j]]
+		if len(ni) != len(nj) {
+			return len(ni) > len(nj)
+		}
+		return oldKeys[i] < oldKeys[j]
+	})
+
+	return oldKeys
+
+}
+
+// deprecatedKey returns the deprecated key holding the value of the given key,
+// if the key is not defined or only defined by default.
+func (self *configImpl) deprecatedKey(key string) (string, bool) {
+
+	for _, oldKey := range self.oldKeys {
+
+		old, p := subKey(key, self.aliases[oldKey], oldKey)
+		if !p {
+			continue
+		}
+
+		if _, p := self.loadRaw(old); !p {
+			continue
+		}
+
+		if origin, p := self.originRaw(key); !p || origin == ORIGIN_DEFAULT {
+			return old, true
+		}
+
+	}
+
+	return "", false
+
+}
+
+// aliasedKeys returns the new keys of the defined deprecated keys.
+func (self *configImpl) aliasedKeys(keys []string) []string {
+
+	aliased := make([]string, 0)
+	for _, key := range keys {
+		for _, oldKey := range self.oldKeys {
+			if k, p := subKey(key, oldKey, self.aliases[oldKey]); p {
+				aliased = append(aliased, k)
+			}
+		}
+	}
+
+	return aliased
+
+}
+
+/*
+ * Deprecation hook
+ */
+
+// DeprecationHook is notified each time a deprecated key is used instead of
+// its new key. The listeners are called once per deprecated key, and the
+// usages recorded before the subscription of a listener are replayed.
+type DeprecationHook struct {
+	mu        sync.Mutex
+	usages    [][2]string
+	used      map[string]bool
+	listeners []func(oldKey, newKey string)
+}
+
+func NewDeprecationHook() *DeprecationHook {
+	return &DeprecationHook{
+		usages:    [][2]string{},
+		used:      map[string]bool{},
+		listeners: []func(string, string){},
+	}
+}
+
+func (self *DeprecationHook) Subscribe(listener func(oldKey, newKey string)) {
+
+	self.mu.Lock()
+	self.listeners = append(self.listeners, listener)
+	usages := self.usages
+	self.mu.Unlock()
+
+	for _, usage := range usages {
+		listener(usage[0], usage[1])
+	}
+
+}
+
+func (self *DeprecationHook) Notify(oldKey, newKey string) {
+
+	if self == nil {
+		return
+	}
+
+	self.mu.Lock()
+	if self.used[oldKey] {
+		self.mu.Unlock()
+		return
+	}
+	self.used[oldKey] = true
+	self.usages = append(self.usages, [2]string{oldKey, newKey})
+	listeners := self.listeners
+	self.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(oldKey, newKey)
+	}
+
+}
+
+// Usages returns the deprecated keys used so far, mapped to their new keys.
+func (self *DeprecationHook) Usages() map[string]string {
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	usages := make(map[string]string, len(self.usages))
+	for _, usage := range self.usages {
+		usages[usage[0]] = usage[1]
+	}
+
+	return usages
+
+}
+
+func (self *DeprecationHook) String() string {
+
+	usages := self.Usages()
+
+	keys := make([]string, 0, len(usages))
+	for k := range usages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s -> %s\n", k, usages[k])
+	}
+
+	return b.String()
+
+}
+
+func init() {
+
+	ioc.PutNamedFactory("Deprecation hook",
+		func() (*DeprecationHook, error) { return NewDeprecationHook(), nil })
+
+}
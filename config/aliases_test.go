@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aliases", func() {
+
+	var backup map[string]string
+
+	BeforeEach(func() {
+		backup = BackupAliases()
+	})
+
+	AfterEach(func() {
+		RestoreAliases(backup)
+	})
+
+	It("should resolve renamed keys and their children", func() {
+
+		Alias("db", "database")
+		TestMap(map[string]string{
+			"db.host":      "localhost",
+			"db.port":      "5432",
+			"url":          "${database.host}:${database.port}",
+			"database.ssl": "true",
+		})
+
+		ioc.CallInjected(func(config Configuration, hook *DeprecationHook) {
+
+			Expect(config.Get("database.host")).To(Equal("localhost"))
+			Expect(config.Get("url")).To(Equal("localhost:5432"))
+			Expect(config.Get("database.ssl")).To(Equal("true"))
+			Expect(config.Keys()).To(ContainElements("database.host", "database.port", "database.ssl"))
+
+			Expect(hook.Usages()).To(Equal(map[string]string{
+				"db.host": "database.host",
+				"db.port": "database.port",
+			}))
+
+		})
+
+	})
+
+	It("should prefer the new key", func() {
+
+		Alias("singer", "band.singer")
+		TestMap(map[string]string{
+			"singer":      "Frank Black",
+			"band.singer": "Black Francis",
+		})
+
+		ioc.CallInjected(func(config Configuration, hook *DeprecationHook) {
+			Expect(config.Get("band.singer")).To(Equal("Black Francis"))
+			Expect(hook.Usages()).To(BeEmpty())
+		})
+
+	})
+
+	It("should prefer the most specific alias", func() {
+
+		Alias("db", "database")
+		Alias("db_host", "database.host")
+		Alias("host", "database.host")
+		TestMap(map[string]string{
+			"db.host": "localhost",
+			"db_host": "db.local",
+			"host":    "host.local",
+		})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("database.host")).To(Equal("db.local"))
+		})
+
+	})
+
+	It("should notify the listeners once per key", func() {
+
+		hook := NewDeprecationHook()
+		hook.Notify("old", "new")
+
+		notified := []string{}
+		hook.Subscribe(func(oldKey, newKey string) {
+			notified = append(notified, oldKey+" -> "+newKey)
+		})
+		hook.Notify("old", "new")
+		hook.Notify("older", "new")
+
+		Expect(notified).To(Equal([]string{"old -> new", "older -> new"}))
+
+	})
+
+})
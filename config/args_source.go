@@ -2,13 +2,104 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/b-charles/pigs/ioc"
 	"github.com/b-charles/pigs/json"
 )
 
+/*
+ * Declared arguments
+ */
+
+// Arg declares a command line argument. It's a shortcut to declare a key (see
+// Key) with a short name: a flag is declared as a boolean key, which never
+// consumes the next argument as its value.
+type Arg struct {
+	Name        string
+	Short       rune
+	Flag        bool
+	Description string
+}
+
+var HELP_ARG = Arg{Name: HELP_KEY.Name, Short: HELP_KEY.Short, Flag: true, Description: HELP_KEY.Description}
+
+func (self Arg) key() Key {
+	key := Key{Name: self.Name, Short: self.Short, Description: self.Description}
+	if self.Flag {
+		key.Type = TypeBool
+	}
+	return key
+}
+
+func DeclareArg(arg Arg) {
+	Declare(arg.key())
+}
+
+func DeclareArgs(args ...Arg) {
+	for _, arg := range args {
+		DeclareArg(arg)
+	}
+}
+
+/*
+ * Help
+ */
+
+// ArgsHelp returns a help text describing the declared keys as command line
+// arguments.
+func ArgsHelp() string {
+
+	keys := NewSchema().Keys()
+
+	lines := make([]string, len(keys))
+	width := 0
+	for i, key := range keys {
+
+		var b strings.Builder
+		if key.Short != 0 {
+			fmt.Fprintf(&b, "-%c, ", key.Short)
+		} else {
+			b.WriteString("    ")
+		}
+		fmt.Fprintf(&b, "--%s", key.Name)
+		if key.typ() != TypeBool {
+			fmt.Fprintf(&b, " <%s>", key.typ())
+		}
+
+		lines[i] = b.String()
+		if l := len(lines[i]); l > width {
+			width = l
+		}
+
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s [arguments...]\n", filepath.Base(os.Args[0]))
+	for i, key := range keys {
+		fmt.Fprintf(&b, "  %-*s  %s", width, lines[i], key.Description)
+		if key.Default != "" {
+			fmt.Fprintf(&b, " (default: %s)", key.Default)
+		}
+		if key.Required {
+			b.WriteString(" (required)")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+
+}
+
+/*
+ * Parsing
+ */
+
 var (
 	valueRegexp       *regexp.Regexp = regexp.MustCompile("^--?([^=]+)=(.*)$")
 	valueRegexpSimple *regexp.Regexp = regexp.MustCompile("^--?([^=]+)='(.*)'$")
@@ -38,46 +129,132 @@ var (
 	noboolRegexp *regexp.Regexp = regexp.MustCompile("^--?no-([^=]+)$")
 )
 
-func keyboolArg(arg string) (string, string, bool) {
+// isFlag returns true if the key is declared as a boolean, which never
+// consumes the next argument as its value.
+func isFlag(key Key) bool {
+	return key.typ() == TypeBool
+}
 
-	match := noboolRegexp.FindStringSubmatch(arg)
-	if match != nil {
-		return match[1], "false", true
+// shortArgs returns the declared keys of a bundle of short flags (e.g.
+// '-xvf'), or false if the argument is not a bundle of declared short names.
+func shortArgs(arg string) ([]Key, bool) {
+
+	if len(arg) < 2 || arg[0] != '-' || arg[1] == '-' {
+		return nil, false
 	}
 
-	match = boolRegexp.FindStringSubmatch(arg)
-	if match != nil {
-		return match[1], "true", true
+	shorts := []rune(arg[1:])
+	args := make([]Key, 0, len(shorts))
+	for i, short := range shorts {
+		if declared, p := declaredShort(short); !p {
+			return nil, false
+		} else if !isFlag(declared) && i < len(shorts)-1 {
+			return nil, false
+		} else {
+			args = append(args, declared)
+		}
 	}
 
-	return "", "", false
+	return args, true
 
 }
 
-func ParseArgs(args []string) (map[string]string, error) {
+// argName converts a single-dashed short name to its declared long name.
+func argName(arg, key string) string {
+	if !strings.HasPrefix(arg, "--") {
+		if runes := []rune(key); len(runes) == 1 {
+			if declared, p := declaredShort(runes[0]); p {
+				return declared.Name
+			}
+		}
+	}
+	return key
+}
+
+// takesValue returns true if the argument at the given position consumes the
+// next argument as its value: only a declared key which is not a boolean does.
+func takesValue(name string, args []string, i int) bool {
+	declared, p := declaredKey(name)
+	return p && !isFlag(declared) && i+1 < len(args)
+}
+
+// ParseCommandLine parses the given arguments and returns the configuration
+// values and the positional arguments.
+func ParseCommandLine(args []string) (map[string]string, []string, error) {
 
 	env := make(map[string]string)
+	positionals := make([]string, 0)
 
-	for _, arg := range args {
+	for i := 0; i < len(args); i++ {
+
+		arg := args[i]
+
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			break
+		}
+
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			positionals = append(positionals, arg)
+			continue
+		}
 
 		if key, value, ok := keyvalueArg(arg); ok {
-			env[key] = value
+			env[argName(arg, key)] = value
 			continue
 		}
 
-		if key, value, ok := keyboolArg(arg); ok {
-			env[key] = value
+		if shorts, ok := shortArgs(arg); ok {
+			for _, short := range shorts {
+				if isFlag(short) {
+					env[short.Name] = "true"
+				} else if i+1 < len(args) {
+					i++
+					env[short.Name] = args[i]
+				} else {
+					return env, positionals, fmt.Errorf("Missing value for argument '%s'.", arg)
+				}
+			}
 			continue
 		}
 
-		return env, fmt.Errorf("Can't parse argument '%s': unknown pattern.", arg)
+		if match := noboolRegexp.FindStringSubmatch(arg); match != nil {
+			env[argName(arg, match[1])] = "false"
+			continue
+		}
+
+		if match := boolRegexp.FindStringSubmatch(arg); match != nil {
+			name := argName(arg, match[1])
+			if takesValue(name, args, i) {
+				i++
+				env[name] = args[i]
+			} else if declared, p := declaredKey(name); p && !isFlag(declared) {
+				return env, positionals, fmt.Errorf("Missing value for argument '%s'.", arg)
+			} else {
+				env[name] = "true"
+			}
+			continue
+		}
+
+		return env, positionals, fmt.Errorf("Can't parse argument '%s': unknown pattern.", arg)
 
 	}
 
-	return env, nil
+	return env, positionals, nil
+
+}
 
+// ParseArgs parses the given arguments and returns the configuration values,
+// ignoring the positional arguments.
+func ParseArgs(args []string) (map[string]string, error) {
+	env, _, err := ParseCommandLine(args)
+	return env, err
 }
 
+/*
+ * Config source
+ */
+
 type ArgsConfigSource ConfigSource
 
 var CONFIG_SOURCE_PRIORITY_ARGS = 100
@@ -86,6 +263,11 @@ type ArgsConfigSourceImpl struct {
 	source map[string]string
 }
 
+func NewArgsConfigSource(args []string) (*ArgsConfigSourceImpl, error) {
+	m, err := ParseArgs(args)
+	return &ArgsConfigSourceImpl{m}, err
+}
+
 func (self *ArgsConfigSourceImpl) GetPriority() int {
 	return CONFIG_SOURCE_PRIORITY_ARGS
 }
@@ -105,15 +287,60 @@ func (self *ArgsConfigSourceImpl) String() string {
 	return self.Json().String()
 }
 
+/*
+ * Positional arguments
+ */
+
+// PositionalArgs are the command line arguments which are not options.
+type PositionalArgs []string
+
+func NewPositionalArgs(args []string) (PositionalArgs, error) {
+	_, positionals, err := ParseCommandLine(args)
+	return PositionalArgs(positionals), err
+}
+
+/*
+ * Help
+ */
+
+// HelpRequested returns true if the key 'help' is true (e.g. with the
+// arguments '--help' or '-h').
+func HelpRequested(config Configuration) (bool, error) {
+	if value, p, err := config.Lookup(HELP_KEY.Name); err != nil || !p {
+		return false, err
+	} else if help, err := strconv.ParseBool(value); err != nil {
+		return false, fmt.Errorf("Invalid value '%s' for the argument '%s': %w", value, HELP_KEY.Name, err)
+	} else {
+		return help, nil
+	}
+}
+
+// PrintHelp writes the help of the arguments if it's requested, and returns
+// true in this case.
+func PrintHelp(config Configuration, w io.Writer) (bool, error) {
+
+	if help, err := HelpRequested(config); err != nil || !help {
+		return false, err
+	}
+
+	_, err := fmt.Fprint(w, ArgsHelp())
+	return err == nil, err
+
+}
+
 func init() {
 
 	ioc.DefaultPutNamedFactory("Args config source (default)",
 		func() (*ArgsConfigSourceImpl, error) {
-			m, err := ParseArgs(os.Args[1:])
-			return &ArgsConfigSourceImpl{m}, err
+			return NewArgsConfigSource(os.Args[1:])
 		}, func(ArgsConfigSource) {})
 
 	ioc.PutNamedFactory("Args config source (promoter)",
 		func(v ArgsConfigSource) (ConfigSource, error) { return v, nil })
 
+	ioc.DefaultPutNamedFactory("Positional arguments (default)",
+		func() (PositionalArgs, error) {
+			return NewPositionalArgs(os.Args[1:])
+		})
+
 }
@@ -1,11 +1,25 @@
 package config_test
 
 import (
+	"strings"
+
 	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+func init() {
+	DeclareArgs(
+		Arg{Name: "verbose", Short: 'v', Flag: true, Description: "Talk a lot."},
+		Arg{Name: "extract", Short: 'x', Flag: true, Description: "Extract files."},
+		Arg{Name: "file", Short: 'f', Description: "The archive."},
+	)
+	DeclareKeys(
+		Key{Name: "port", Type: TypeInt, Description: "The listening port."},
+	)
+}
+
 func parse(args []string) map[string]string {
 	if env, err := ParseArgs(args); err != nil {
 		panic(err)
@@ -46,10 +60,83 @@ var _ = Describe("Args", func() {
 		Expect(source).To(HaveKeyWithValue("yes", "false"))
 	})
 
-	It("should returns an error for unknown pattern", func() {
-		Expect(func() {
-			parse([]string{"hello=goodbye"})
-		}).To(Panic())
+	It("should parse separated value", func() {
+		source := parse([]string{"--file", "Bohemian Rhapsody"})
+		Expect(source).To(HaveKeyWithValue("file", "Bohemian Rhapsody"))
+	})
+
+	It("should not consume a value for an undeclared argument", func() {
+		env, positionals, err := ParseCommandLine([]string{"--queen", "Bohemian Rhapsody"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(env).To(Equal(map[string]string{"queen": "true"}))
+		Expect(positionals).To(Equal([]string{"Bohemian Rhapsody"}))
+	})
+
+	It("should parse declared value starting with a dash", func() {
+		source := parse([]string{"--port", "-1"})
+		Expect(source).To(HaveKeyWithValue("port", "-1"))
+	})
+
+	It("should return an error for missing value of declared argument", func() {
+		_, err := ParseArgs([]string{"--port"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should parse short flags bundle", func() {
+		source := parse([]string{"-xvf", "archive.tar"})
+		Expect(source).To(HaveKeyWithValue("extract", "true"))
+		Expect(source).To(HaveKeyWithValue("verbose", "true"))
+		Expect(source).To(HaveKeyWithValue("file", "archive.tar"))
+	})
+
+	It("should parse positional arguments", func() {
+		env, positionals, err := ParseCommandLine([]string{
+			"hello=goodbye", "--verbose", "first", "--", "--second", "-x"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(env).To(Equal(map[string]string{"verbose": "true"}))
+		Expect(positionals).To(Equal([]string{"hello=goodbye", "first", "--second", "-x"}))
+	})
+
+	It("should inject positional arguments", func() {
+
+		positionals, err := NewPositionalArgs([]string{"--abba=Waterloo", "SOS"})
+		Expect(err).ToNot(HaveOccurred())
+		ioc.TestPut(positionals)
+
+		ioc.CallInjected(func(positionals PositionalArgs) {
+			Expect(positionals).To(Equal(PositionalArgs{"SOS"}))
+		})
+
+	})
+
+	It("should detect and print the help request", func() {
+
+		source, err := NewArgsConfigSource([]string{"-h"})
+		Expect(err).ToNot(HaveOccurred())
+		config, err := CreateConfiguration([]ConfigSource{source}, nil, NewSchema())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(HelpRequested(config)).To(BeTrue())
+
+		var b strings.Builder
+		Expect(PrintHelp(config, &b)).To(BeTrue())
+		Expect(b.String()).To(ContainSubstring("-h, --help"))
+
+		source, err = NewArgsConfigSource([]string{"--abba=Waterloo"})
+		Expect(err).ToNot(HaveOccurred())
+		config, err = CreateConfiguration([]ConfigSource{source}, nil, NewSchema())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(HelpRequested(config)).To(BeFalse())
+		Expect(PrintHelp(config, &b)).To(BeFalse())
+
+	})
+
+	It("should generate help", func() {
+		help := ArgsHelp()
+		Expect(help).To(ContainSubstring("-h, --help"))
+		Expect(help).To(ContainSubstring("-f, --file <string>"))
+		Expect(help).To(ContainSubstring("The listening port."))
 	})
 
 })
@@ -3,8 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -26,49 +27,6 @@ type ConfigSource interface {
  * Config
  */
 
-var placeholderRegexp *regexp.Regexp = regexp.MustCompile("\\${\\s*([^{}]+)\\s*}")
-
-type pstring struct {
-	p   bool
-	str string
-}
-
-func resolveValue(raws sync.Map, key string, recfun func(string) (pstring, error)) (pstring, error) {
-
-	if uncastedValue, p := raws.Load(key); !p {
-
-		return pstring{false, ""}, nil
-
-	} else {
-
-		value := uncastedValue.(string)
-
-		replace := true
-		for replace {
-
-			replace = false
-			if matches := placeholderRegexp.FindAllStringSubmatch(value, -1); matches != nil {
-				for _, match := range matches {
-
-					if subpvalue, err := recfun(match[1]); err != nil {
-						return subpvalue, err
-					} else if subpvalue.p {
-						value = strings.Replace(value, match[0], subpvalue.str, -1)
-						replace = true
-						break
-					}
-
-				}
-			}
-
-		}
-
-		return pstring{true, value}, nil
-
-	}
-
-}
-
 type MutableConfig interface {
 	HasKey(string) bool
 	Keys() []string
@@ -84,46 +42,166 @@ type Configuration interface {
 	GetRaw(string) (string, bool)
 	Lookup(string) (string, bool, error)
 	Get(string) string
+	WithOverrides(map[string]string) (Configuration, error)
 }
 
+// configImpl is the default Configuration. A configuration derived with
+// WithOverrides only records the overridden values, the other ones are read
+// from the parent.
 type configImpl struct {
-	mutable  bool
-	raws     sync.Map
-	resolved memfun.MemFun[string, pstring]
+	mutable      bool
+	strict       bool
+	parent       *configImpl
+	aliases      map[string]string
+	oldKeys      []string
+	deprecations *DeprecationHook
+	schema       *Schema
+	raws         sync.Map
+	origin       string
+	origins      sync.Map
+	resolvers    map[string]PlaceholderResolver
+	resolved     memfun.MemFun[string, pstring]
 }
 
-func newConfigImpl() *configImpl {
+func newConfigImpl(resolvers []PlaceholderResolver) (*configImpl, error) {
 
 	config := new(configImpl)
 	config.mutable = false
+
+	config.resolvers = make(map[string]PlaceholderResolver, len(resolvers))
+	for _, resolver := range resolvers {
+		prefix := resolver.GetPrefix()
+		if old, p := config.resolvers[prefix]; p {
+			return nil, fmt.Errorf("Two placeholder resolvers '%v' and '%v' are defined for the same prefix '%s'.",
+				old, resolver, prefix)
+		}
+		config.resolvers[prefix] = resolver
+	}
+
 	config.resolved = memfun.NewMemFun(func(key string, recfun func(string) (pstring, error)) (pstring, error) {
-		return resolveValue(config.raws, key, recfun)
+		return config.resolveValue(config.strict, key, recfun)
+	})
+
+	return config, nil
+
+}
+
+func (self *configImpl) strictPlaceholders() (bool, error) {
+
+	if value, p := self.load(CONFIG_PLACEHOLDERS_STRICT); !p {
+		return false, nil
+	} else if strict, err := strconv.ParseBool(value); err != nil {
+		return false, fmt.Errorf("Invalid value '%v' for '%s': %w", value, CONFIG_PLACEHOLDERS_STRICT, err)
+	} else {
+		return strict, nil
+	}
+
+}
+
+// loadRaw returns the raw value of the key, defined in this configuration or
+// in its parents.
+func (self *configImpl) loadRaw(key string) (string, bool) {
+	if value, p := self.raws.Load(key); p {
+		return value.(string), true
+	} else if self.parent != nil {
+		return self.parent.loadRaw(key)
+	} else {
+		return "", false
+	}
+}
+
+// load returns the raw value of the key, or the value of its deprecated alias.
+func (self *configImpl) load(key string) (string, bool) {
+	if old, p := self.deprecatedKey(key); p {
+		self.deprecations.Notify(old, key)
+		return self.loadRaw(old)
+	}
+	return self.loadRaw(key)
+}
+
+// rawMap returns all the raw values, including the ones of the parents.
+func (self *configImpl) rawMap() map[string]string {
+
+	r := make(map[string]string)
+	if self.parent != nil {
+		r = self.parent.rawMap()
+	}
+
+	self.raws.Range(func(key, value any) bool {
+		r[key.(string)] = value.(string)
+		return true
 	})
 
-	return config
+	return r
 
 }
 
 func (self *configImpl) HasKey(key string) bool {
-	_, p := self.raws.Load(key)
+	_, p := self.load(key)
 	return p
 }
 
 func (self *configImpl) Keys() []string {
-	keys := make([]string, 0)
-	self.raws.Range(func(k, v any) bool {
-		keys = append(keys, k.(string))
-		return true
-	})
+
+	raws := self.rawMap()
+	keys := make([]string, 0, len(raws))
+	for k := range raws {
+		keys = append(keys, k)
+	}
+
+	for _, k := range self.aliasedKeys(keys) {
+		if _, p := raws[k]; !p {
+			raws[k] = ""
+			keys = append(keys, k)
+		}
+	}
+
 	return keys
+
 }
 
 func (self *configImpl) GetRaw(key string) (string, bool) {
-	value, p := self.raws.Load(key)
-	return value.(string), p
+	return self.load(key)
 }
 
 func (self *configImpl) Lookup(key string) (string, bool, error) {
+	result, err := self.lookup(key)
+	return result.str, result.p, err
+}
+
+// Dependencies returns the keys used by the placeholders of the value of the
+// key, directly or not, in lexicographical order.
+func (self *configImpl) Dependencies(key string) ([]string, error) {
+	result, err := self.lookup(key)
+	return distinct(result.deps), err
+}
+
+// Externals returns the prefixes of the PlaceholderResolvers used by the
+// placeholders of the value of the key, directly or not, in lexicographical
+// order.
+func (self *configImpl) Externals(key string) ([]string, error) {
+	result, err := self.lookup(key)
+	return distinct(result.externals), err
+}
+
+// distinct returns the distinct values, in lexicographical order.
+func distinct(values []string) []string {
+
+	set := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !set[value] {
+			set[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+
+	return unique
+
+}
+
+func (self *configImpl) lookup(key string) (pstring, error) {
 
 	var (
 		result pstring
@@ -132,25 +210,30 @@ func (self *configImpl) Lookup(key string) (string, bool, error) {
 
 	if self.mutable {
 
+		strict, e := self.strictPlaceholders()
+		if e != nil {
+			return pstring{}, e
+		}
+
 		called := map[string]bool{key: true}
 
 		var recfun func(k string) (pstring, error)
 		recfun = func(k string) (pstring, error) {
 
 			if _, p := called[k]; p {
-				return pstring{false, ""}, memfun.CyclicLoopError[string]{
+				return pstring{}, memfun.CyclicLoopError[string]{
 					Stack: []string{k},
 				}
 			}
 
-			called[key] = true
-			defer delete(called, key)
+			called[k] = true
+			defer delete(called, k)
 
-			r, e := resolveValue(self.raws, key, recfun)
+			r, e := self.resolveValue(strict, k, recfun)
 
 			if e != nil {
 				if cyclic, ok := e.(memfun.CyclicLoopError[string]); ok {
-					return r, cyclic.Append(key)
+					return r, cyclic.Append(k)
 				}
 			}
 
@@ -158,7 +241,13 @@ func (self *configImpl) Lookup(key string) (string, bool, error) {
 
 		}
 
-		result, err = resolveValue(self.raws, key, recfun)
+		result, err = self.resolveValue(strict, key, recfun)
+
+		if err != nil {
+			if cyclic, ok := err.(memfun.CyclicLoopError[string]); ok {
+				err = cyclic.Append(key)
+			}
+		}
 
 	} else {
 
@@ -174,7 +263,7 @@ func (self *configImpl) Lookup(key string) (string, bool, error) {
 			b.WriteString("Cyclic loop detected: ")
 
 			fmtElt := func(k string) string {
-				v, _ := self.raws.Load(k)
+				v, _ := self.load(k)
 				return fmt.Sprintf("%s: '%v'", k, v)
 			}
 
@@ -184,12 +273,12 @@ func (self *configImpl) Lookup(key string) (string, bool, error) {
 				b.WriteString(fmtElt(cyclic.Stack[i]))
 			}
 
-			return "", false, errors.New(b.String())
+			return pstring{}, errors.New(b.String())
 
 		}
 	}
 
-	return result.str, result.p, err
+	return result, err
 
 }
 
@@ -203,17 +292,105 @@ func (self *configImpl) Get(key string) string {
 
 func (self *configImpl) Set(key, value string) {
 	self.raws.Store(key, value)
+	self.origins.Store(key, self.origin)
 }
 
-func (self *configImpl) Json() json.JsonNode {
+func (self *configImpl) originRaw(key string) (string, bool) {
+	if origin, p := self.origins.Load(key); p {
+		return origin.(string), true
+	} else if self.parent != nil {
+		return self.parent.originRaw(key)
+	} else {
+		return "", false
+	}
+}
 
-	r := make(map[string]string)
-	self.raws.Range(func(key, value any) bool {
-		r[key.(string)] = value.(string)
-		return true
+// Origin returns the name of the source which has defined the value of the
+// key.
+func (self *configImpl) Origin(key string) (string, bool) {
+	if old, p := self.deprecatedKey(key); p {
+		return self.originRaw(old)
+	}
+	return self.originRaw(key)
+}
+
+// WithOverrides returns a derived configuration, where the given values
+// override the values of this configuration. The placeholders of the values of
+// this configuration are resolved with the overridden values. The declared
+// keys which are overridden, or which depend on an overridden key, are
+// validated against the schema. This configuration is not modified.
+func (self *configImpl) WithOverrides(overrides map[string]string) (Configuration, error) {
+
+	derived := &configImpl{
+		parent:       self,
+		aliases:      self.aliases,
+		oldKeys:      self.oldKeys,
+		deprecations: self.deprecations,
+		schema:       self.schema,
+		resolvers:    self.resolvers,
+		origin:       ORIGIN_OVERRIDE,
+	}
+
+	for k, v := range overrides {
+		derived.Set(k, v)
+	}
+
+	if strict, err := derived.strictPlaceholders(); err != nil {
+		return nil, err
+	} else {
+		derived.strict = strict
+	}
+
+	derived.resolved = memfun.NewMemFun(func(key string, recfun func(string) (pstring, error)) (pstring, error) {
+		return derived.resolveValue(derived.strict, key, recfun)
 	})
 
-	return json.NewJsonObjectStrings(r)
+	if derived.schema != nil {
+		if err := derived.schema.validate(derived, self.overridden(overrides)); err != nil {
+			return nil, err
+		}
+	}
+
+	return derived, nil
+
+}
+
+// overridden returns a predicate accepting the keys whose values are changed
+// by the overrides: the overridden keys, their parents, and the keys
+// depending on them.
+func (self *configImpl) overridden(overrides map[string]string) func(string) bool {
+
+	if _, p := overrides[CONFIG_PLACEHOLDERS_STRICT]; p {
+		return func(string) bool { return true }
+	}
+
+	return func(key string) bool {
+
+		for k := range overrides {
+			if k == key || strings.HasPrefix(k, key+".") {
+				return true
+			}
+		}
+
+		result, err := self.lookup(key)
+		if err != nil {
+			return true
+		}
+		for _, dep := range result.deps {
+			if _, p := overrides[dep]; p {
+				return true
+			}
+		}
+
+		return false
+
+	}
+
+}
+
+func (self *configImpl) Json() json.JsonNode {
+
+	return json.NewJsonObjectStrings(self.rawMap())
 
 }
 
@@ -280,32 +457,80 @@ func RestoreDefault(backup map[string]string) {
  * Factory
  */
 
-func CreateConfiguration(sources []ConfigSource) (Configuration, error) {
+var (
+	ORIGIN_DEFAULT  = "default"
+	ORIGIN_OVERRIDE = "override"
+)
+
+// sourceName returns a short name of the source, from its type (e.g. 'EnvVar'
+// for the type EnvVarConfigSourceImpl).
+func sourceName(source ConfigSource) string {
+	typ := reflect.TypeOf(source)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	name := strings.TrimSuffix(typ.Name(), "Impl")
+	if trimmed := strings.TrimSuffix(name, "ConfigSource"); trimmed != "" {
+		name = trimmed
+	}
+	return name
+}
+
+func CreateConfiguration(sources []ConfigSource, resolvers []PlaceholderResolver, schema *Schema) (Configuration, error) {
 
 	sort.Slice(sources, func(i, j int) bool {
 		return sources[i].GetPriority() < sources[j].GetPriority()
 	})
 
-	conf := newConfigImpl()
-
-	for k, v := range getDefaultConfigMap() {
-		conf.Set(k, v)
+	conf, err := newConfigImpl(resolvers)
+	if err != nil {
+		return nil, err
+	}
+	conf.mutable = true
+	conf.aliases = schema.aliases
+	conf.oldKeys = sortedAliases(schema.aliases)
+	conf.deprecations = schema.deprecations
+	conf.schema = schema
+
+	defaults := getDefaultConfigMap()
+	defaultLayer := conf.newLayer(ORIGIN_DEFAULT)
+	for k, v := range defaults {
+		defaultLayer.Set(k, v)
+	}
+	if err := conf.merge(defaultLayer, schema); err != nil {
+		return nil, err
 	}
+
 	for _, source := range sources {
-		err := source.LoadEnv(conf)
-		if err != nil {
+		layer := conf.newLayer(sourceName(source))
+		if err := source.LoadEnv(layer); err != nil {
 			return nil, fmt.Errorf("Error during loading configuration from '%v': %w", source, err)
 		}
+		if err := conf.merge(layer, schema); err != nil {
+			return nil, fmt.Errorf("Error during merging configuration from '%v': %w", source, err)
+		}
+	}
+
+	if strict, err := conf.strictPlaceholders(); err != nil {
+		return nil, err
+	} else {
+		conf.strict = strict
 	}
 
 	conf.mutable = false
 
+	if err := schema.Validate(conf); err != nil {
+		return nil, err
+	}
+
 	return conf, nil
 
 }
 
 func init() {
 
+	Set(CONFIG_PLACEHOLDERS_STRICT, "false")
+
 	ioc.DefaultPutNamedFactory("Configuration", CreateConfiguration)
 
 }
@@ -33,6 +33,21 @@ func (self *SimpleConfigSource) LoadEnv(config MutableConfig) error {
 	return nil
 }
 
+// Source looking up a key while loading
+
+type LookupConfigSource struct {
+	Key string
+}
+
+func (self *LookupConfigSource) GetPriority() int {
+	return 1
+}
+
+func (self *LookupConfigSource) LoadEnv(config MutableConfig) error {
+	_, _, err := config.Lookup(self.Key)
+	return err
+}
+
 var _ = Describe("Configuration", func() {
 
 	Describe("Default config source", func() {
@@ -144,6 +159,134 @@ var _ = Describe("Configuration", func() {
 
 		})
 
+		It("Should use default values", func() {
+
+			TestMap(map[string]string{
+				"hero":            "Robin",
+				"partner":         "${hero:Alfred}",
+				"butler":          "${butler.name:Alfred}",
+				"villain":         "${villain.name:${villain.default}}",
+				"villain.default": "Joker",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+				Expect(config.Get("partner")).To(Equal("Robin"))
+				Expect(config.Get("butler")).To(Equal("Alfred"))
+				Expect(config.Get("villain")).To(Equal("Joker"))
+			})
+
+		})
+
+		It("Should resolve environment variables", func() {
+
+			GinkgoT().Setenv("PIGS_TEST_CITY", "Gotham")
+
+			TestMap(map[string]string{
+				"city":    "${env:PIGS_TEST_CITY}",
+				"country": "${env:PIGS_TEST_COUNTRY:USA}",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+				Expect(config.Get("city")).To(Equal("Gotham"))
+				Expect(config.Get("country")).To(Equal("USA"))
+			})
+
+		})
+
+		It("Should not resolve escaped placeholders", func() {
+
+			TestMap(map[string]string{
+				"name":   "Batman",
+				"syntax": "Use $${name} to get '${name}'",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+				Expect(config.Get("syntax")).To(Equal("Use ${name} to get 'Batman'"))
+			})
+
+		})
+
+		It("Should return an error for unresolved placeholders in strict mode", func() {
+
+			TestMap(map[string]string{
+				CONFIG_PLACEHOLDERS_STRICT: "true",
+				"whoami":                   "I'm ${name}",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+				_, _, err := config.Lookup("whoami")
+				Expect(err).To(MatchError(ContainSubstring("${name}")))
+			})
+
+		})
+
+		It("Should return an error for an invalid strict mode while loading", func() {
+
+			_, err := CreateConfiguration([]ConfigSource{
+				&SimpleConfigSource{0, map[string]string{
+					CONFIG_PLACEHOLDERS_STRICT: "maybe",
+					"whoami":                   "I'm ${name}",
+				}},
+				&LookupConfigSource{"whoami"},
+			}, nil, NewSchema())
+			Expect(err).To(MatchError(ContainSubstring("Error during loading configuration")))
+			Expect(err).To(MatchError(ContainSubstring("Invalid value 'maybe'")))
+
+		})
+
+		It("Should detect cyclic loops", func() {
+
+			TestMap(map[string]string{
+				"chicken": "${egg}",
+				"egg":     "${chicken}",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+				_, _, err := config.Lookup("chicken")
+				Expect(err).To(MatchError(ContainSubstring("Cyclic loop detected")))
+			})
+
+		})
+
+	})
+
+	Describe("Overrides", func() {
+
+		It("Should derive a configuration with overridden values", func() {
+
+			TestMap(map[string]string{
+				"tenant":   "wayne",
+				"db.url":   "postgres://db/${tenant}",
+				"greeting": "Hello",
+			})
+
+			ioc.CallInjected(func(config Configuration) {
+
+				derived, err := config.WithOverrides(map[string]string{
+					"tenant": "stark",
+					"armor":  "Mark ${mark:42}",
+				})
+				Expect(err).To(Succeed())
+
+				Expect(derived.Get("db.url")).To(Equal("postgres://db/stark"))
+				Expect(derived.Get("greeting")).To(Equal("Hello"))
+				Expect(derived.Get("armor")).To(Equal("Mark 42"))
+				Expect(derived.Keys()).To(ContainElements("tenant", "db.url", "greeting", "armor"))
+				raw, _ := derived.GetRaw("tenant")
+				Expect(raw).To(Equal("stark"))
+
+				nested, err := derived.WithOverrides(map[string]string{"mark": "7"})
+				Expect(err).To(Succeed())
+				Expect(nested.Get("armor")).To(Equal("Mark 7"))
+				Expect(nested.Get("db.url")).To(Equal("postgres://db/stark"))
+
+				Expect(config.Get("db.url")).To(Equal("postgres://db/wayne"))
+				Expect(config.HasKey("armor")).To(BeFalse())
+
+			})
+
+		})
+
 	})
 
 })
@@ -0,0 +1,375 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/b-charles/pigs/ioc"
+	"github.com/b-charles/pigs/json"
+)
+
+var (
+	CONFIG_DUMP        = "config.dump"
+	CONFIG_DUMP_MASKED = "config.dump.masked"
+	DUMP_MASK          = "******"
+	DUMP_FORMATS       = []string{"json", "yaml", "properties"}
+)
+
+/*
+ * Dump
+ */
+
+// DumpEntry is a resolved configuration value, with the name of the source
+// which has defined it.
+type DumpEntry struct {
+	Key    string
+	Value  string
+	Source string
+	Masked bool
+}
+
+// Dump is a snapshot of the resolved configuration, sorted by keys.
+type Dump struct {
+	entries []DumpEntry
+}
+
+// secretKey returns true if the key is declared as secret, or if its last
+// segment contains one of the secret patterns.
+func secretKey(key string, schema *Schema, patterns []string) bool {
+
+	for _, declared := range schema.Keys() {
+		if declared.Name == key {
+			if declared.Secret {
+				return true
+			}
+			break
+		}
+	}
+
+	last := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, pattern := range patterns {
+		if pattern != "" && strings.Contains(last, pattern) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// NewDump resolves all the values of the configuration. The values of the
+// secret keys, of the keys interpolating them and of the keys resolved by a
+// PlaceholderResolver (files, environment variables...) are masked.
+func NewDump(config Configuration, schema *Schema) (*Dump, error) {
+
+	patterns := make([]string, 0)
+	if secrets, _, err := config.Lookup(CONFIG_DUMP_MASKED); err != nil {
+		return nil, err
+	} else {
+		for _, pattern := range strings.Split(secrets, ",") {
+			patterns = append(patterns, strings.ToLower(strings.TrimSpace(pattern)))
+		}
+	}
+
+	origins, _ := config.(interface{ Origin(string) (string, bool) })
+	dependencies, _ := config.(interface {
+		Dependencies(string) ([]string, error)
+	})
+	externals, _ := config.(interface {
+		Externals(string) ([]string, error)
+	})
+
+	masked := func(key string) bool {
+		raw, _ := config.GetRaw(key)
+		return IsEncrypted(raw) || secretKey(key, schema, patterns)
+	}
+
+	keys := config.Keys()
+	sort.Strings(keys)
+
+	entries := make([]DumpEntry, 0, len(keys))
+	for _, key := range keys {
+
+		value, _, err := config.Lookup(key)
+		if err != nil {
+			return nil, fmt.Errorf("Can not resolve the key '%s': %w", key, err)
+		}
+
+		entry := DumpEntry{Key: key, Value: value}
+		if origins != nil {
+			entry.Source, _ = origins.Origin(key)
+		}
+		entry.Masked = masked(key)
+		if !entry.Masked && externals != nil {
+			resolvers, err := externals.Externals(key)
+			if err != nil {
+				return nil, fmt.Errorf("Can not resolve the key '%s': %w", key, err)
+			}
+			entry.Masked = len(resolvers) > 0
+		}
+		if !entry.Masked && dependencies != nil {
+			deps, err := dependencies.Dependencies(key)
+			if err != nil {
+				return nil, fmt.Errorf("Can not resolve the key '%s': %w", key, err)
+			}
+			for _, dep := range deps {
+				if masked(dep) {
+					entry.Masked = true
+					break
+				}
+			}
+		}
+		if entry.Masked {
+			entry.Value = DUMP_MASK
+		}
+
+		entries = append(entries, entry)
+
+	}
+
+	return &Dump{entries}, nil
+
+}
+
+// ParseDump reads a dump previously formatted in Json.
+func ParseDump(content string) (*Dump, error) {
+
+	node, err := json.ParseString(content)
+	if err != nil {
+		return nil, err
+	} else if !node.IsObject() {
+		return nil, fmt.Errorf("A configuration dump should be a Json object: %v", node)
+	}
+
+	keys := node.GetKeys()
+	sort.Strings(keys)
+
+	entries := make([]DumpEntry, 0, len(keys))
+	for _, key := range keys {
+
+		member := node.GetMember(key)
+		value := member.GetMember("value")
+		if !value.IsString() {
+			return nil, fmt.Errorf("No value defined for the key '%s' in the dump.", key)
+		}
+
+		entry := DumpEntry{Key: key, Value: value.AsString()}
+		if source := member.GetMember("source"); source.IsString() {
+			entry.Source = source.AsString()
+		}
+		entry.Masked = entry.Value == DUMP_MASK
+
+		entries = append(entries, entry)
+
+	}
+
+	return &Dump{entries}, nil
+
+}
+
+func (self *Dump) Entries() []DumpEntry {
+	return self.entries
+}
+
+func (self *Dump) Json() json.JsonNode {
+
+	members := make(map[string]DumpEntry, len(self.entries))
+	for _, entry := range self.entries {
+		members[entry.Key] = entry
+	}
+
+	return json.NewJsonObjectMapped(members, func(entry DumpEntry) json.JsonNode {
+		b := json.NewJsonBuilder()
+		b.SetString("value", entry.Value)
+		if entry.Source != "" {
+			b.SetString("source", entry.Source)
+		}
+		return b.Build()
+	})
+
+}
+
+func (self *Dump) Yaml() string {
+
+	var b strings.Builder
+	for _, entry := range self.entries {
+		fmt.Fprintf(&b, "%s: %s", strconv.Quote(entry.Key), strconv.Quote(entry.Value))
+		if entry.Source != "" {
+			fmt.Fprintf(&b, " # %s", entry.Source)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+
+}
+
+func escapeProperty(value string, key bool) string {
+
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case key && (r == '=' || r == ':' || r == ' '):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case !key && i == 0 && r == ' ':
+			b.WriteString(`\ `)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+
+}
+
+func (self *Dump) Properties() string {
+
+	var b strings.Builder
+	for _, entry := range self.entries {
+		if entry.Source != "" {
+			fmt.Fprintf(&b, "# %s\n", entry.Source)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", escapeProperty(entry.Key, true), escapeProperty(entry.Value, false))
+	}
+
+	return b.String()
+
+}
+
+// Format renders the dump in one of the formats 'json', 'yaml' or
+// 'properties'.
+func (self *Dump) Format(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return self.Json().String(), nil
+	case "yaml":
+		return self.Yaml(), nil
+	case "properties":
+		return self.Properties(), nil
+	default:
+		return "", fmt.Errorf("Unknown dump format '%s', expected one of %v.", format, DUMP_FORMATS)
+	}
+}
+
+func (self *Dump) String() string {
+	return self.Properties()
+}
+
+// PrintDump writes the dump of the configuration if the key 'config.dump'
+// defines a format, and returns true in this case.
+func PrintDump(config Configuration, schema *Schema, w io.Writer) (bool, error) {
+
+	format, p, err := config.Lookup(CONFIG_DUMP)
+	if err != nil || !p || format == "" {
+		return false, err
+	}
+
+	dump, err := NewDump(config, schema)
+	if err != nil {
+		return false, err
+	}
+
+	formatted, err := dump.Format(format)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = fmt.Fprintln(w, formatted)
+	return err == nil, err
+
+}
+
+/*
+ * Diff
+ */
+
+// DumpDiff is a difference between two dumps: a key only defined in the left
+// dump, only defined in the right dump, or defined with different values.
+type DumpDiff struct {
+	Key   string
+	Left  *DumpEntry
+	Right *DumpEntry
+}
+
+func (self DumpDiff) String() string {
+	if self.Right == nil {
+		return fmt.Sprintf("- %s=%s", self.Key, self.Left.Value)
+	} else if self.Left == nil {
+		return fmt.Sprintf("+ %s=%s", self.Key, self.Right.Value)
+	} else {
+		return fmt.Sprintf("~ %s=%s -> %s", self.Key, self.Left.Value, self.Right.Value)
+	}
+}
+
+// Diff compares the values of two dumps. The sources are ignored.
+func (self *Dump) Diff(other *Dump) []DumpDiff {
+
+	diffs := make([]DumpDiff, 0)
+
+	i, j := 0, 0
+	for i < len(self.entries) || j < len(other.entries) {
+
+		switch {
+		case j == len(other.entries) || (i < len(self.entries) && self.entries[i].Key < other.entries[j].Key):
+			diffs = append(diffs, DumpDiff{self.entries[i].Key, &self.entries[i], nil})
+			i++
+		case i == len(self.entries) || other.entries[j].Key < self.entries[i].Key:
+			diffs = append(diffs, DumpDiff{other.entries[j].Key, nil, &other.entries[j]})
+			j++
+		default:
+			if self.entries[i].Value != other.entries[j].Value {
+				diffs = append(diffs, DumpDiff{self.entries[i].Key, &self.entries[i], &other.entries[j]})
+			}
+			i++
+			j++
+		}
+
+	}
+
+	return diffs
+
+}
+
+// FormatDiff renders the differences, one per line.
+func FormatDiff(diffs []DumpDiff) string {
+
+	var b strings.Builder
+	for _, diff := range diffs {
+		b.WriteString(diff.String())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+
+}
+
+func init() {
+
+	DeclareKeys(
+		Key{
+			Name:        CONFIG_DUMP,
+			Description: fmt.Sprintf("Print the resolved configuration in the given format (%s).", strings.Join(DUMP_FORMATS, ", ")),
+		},
+		Key{
+			Name:        CONFIG_DUMP_MASKED,
+			Default:     "password,passwd,secret,token,credential,apikey,api_key,private",
+			Description: "Patterns of the keys masked in the configuration dumps.",
+		},
+	)
+
+	ioc.PutNamedFactory("Configuration dump",
+		func(config Configuration, schema *Schema) (*Dump, error) { return NewDump(config, schema) })
+
+}
@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"strings"
+
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dump", func() {
+
+	var (
+		backup       map[string]string
+		backupSchema map[string]Key
+	)
+
+	BeforeEach(func() {
+		backup = BackupDefault()
+		backupSchema = BackupSchema()
+	})
+
+	AfterEach(func() {
+		RestoreDefault(backup)
+		RestoreSchema(backupSchema)
+	})
+
+	It("should dump the resolved values with their sources", func() {
+
+		Set("band", "Nirvana")
+		Declare(Key{Name: "studio.pin", Secret: true})
+		Test("album", "Nevermind by ${band}")
+		Test("db.password", "Smells Like Teen Spirit")
+		Test("studio.pin", "1991")
+		Test("studio.door", "pin ${studio.pin}")
+
+		ioc.CallInjected(func(dump *Dump) {
+
+			props := dump.Properties()
+			Expect(props).To(ContainSubstring("# default\nband=Nirvana\n"))
+			Expect(props).To(ContainSubstring("# TestSourceEntry\nalbum=Nevermind by Nirvana\n"))
+			Expect(props).To(ContainSubstring("db.password=******\n"))
+			Expect(props).To(ContainSubstring("studio.pin=******\n"))
+			Expect(props).To(ContainSubstring("studio.door=******\n"))
+
+			yaml, err := dump.Format("yaml")
+			Expect(err).To(Succeed())
+			Expect(yaml).To(ContainSubstring(`"album": "Nevermind by Nirvana" # TestSourceEntry`))
+
+			js, err := dump.Format("json")
+			Expect(err).To(Succeed())
+			Expect(js).To(ContainSubstring(`"band":{"value":"Nirvana","source":"default"}`))
+
+			_, err = dump.Format("xml")
+			Expect(err).To(MatchError(ContainSubstring("Unknown dump format 'xml'")))
+
+		})
+
+	})
+
+	It("should print the dump on demand", func() {
+
+		Test(CONFIG_DUMP, "properties")
+		Test("song", "Lithium")
+
+		ioc.CallInjected(func(config Configuration, schema *Schema) {
+			var b strings.Builder
+			printed, err := PrintDump(config, schema, &b)
+			Expect(err).To(Succeed())
+			Expect(printed).To(BeTrue())
+			Expect(b.String()).To(ContainSubstring("song=Lithium\n"))
+		})
+
+	})
+
+	It("should diff two dumps", func() {
+
+		staging, err := ParseDump(`{
+			"db.host":{"value":"staging-db","source":"JsonFiles"},
+			"debug":{"value":"true"},
+			"name":{"value":"app"}
+		}`)
+		Expect(err).To(Succeed())
+
+		prod, err := ParseDump(`{
+			"db.host":{"value":"prod-db","source":"JsonFiles"},
+			"name":{"value":"app"},
+			"replicas":{"value":"3"}
+		}`)
+		Expect(err).To(Succeed())
+
+		Expect(FormatDiff(staging.Diff(prod))).To(Equal(
+			"~ db.host=staging-db -> prod-db\n" +
+				"- debug=true\n" +
+				"+ replicas=3\n"))
+
+		reparsed, err := ParseDump(staging.Json().String())
+		Expect(err).To(Succeed())
+		Expect(staging.Diff(reparsed)).To(BeEmpty())
+
+	})
+
+})
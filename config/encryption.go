@@ -0,0 +1,129 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+/*
+ * Encrypted values
+ */
+
+var (
+	CONFIG_ENCRYPTION_KEY = "config.encryption.key"
+	ENCRYPTED_PREFIX      = "ENC("
+	ENCRYPTED_SUFFIX      = ")"
+)
+
+// IsEncrypted returns true if the value has the form 'ENC(<base64>)'.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ENCRYPTED_PREFIX) && strings.HasSuffix(value, ENCRYPTED_SUFFIX)
+}
+
+func newAead(key string) (cipher.AEAD, error) {
+
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("The encryption key should be base64 encoded: %w", err)
+	}
+
+	block, err := aes.NewCipher(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+
+}
+
+// GenerateEncryptionKey returns a new random AES-256 key, base64 encoded.
+func GenerateEncryptionKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue encrypts the value with AES-GCM and the given base64 encoded
+// key (16, 24 or 32 bytes), and returns it in the form 'ENC(<base64>)'.
+func EncryptValue(key, value string) (string, error) {
+
+	aead, err := newAead(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+
+	return ENCRYPTED_PREFIX + base64.StdEncoding.EncodeToString(sealed) + ENCRYPTED_SUFFIX, nil
+
+}
+
+// DecryptValue decrypts a value in the form 'ENC(<base64>)' with the given
+// base64 encoded key.
+func DecryptValue(key, value string) (string, error) {
+
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("The value '%s' is not encrypted.", value)
+	}
+
+	aead, err := newAead(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value[len(ENCRYPTED_PREFIX) : len(value)-len(ENCRYPTED_SUFFIX)])
+	if err != nil {
+		return "", err
+	} else if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("The encrypted value is too short.")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	if plain, err := aead.Open(nil, nonce, ciphertext, nil); err != nil {
+		return "", err
+	} else {
+		return string(plain), nil
+	}
+
+}
+
+// decrypt decrypts an encrypted value of the configuration, with the key
+// defined in the configuration itself.
+func (self *configImpl) decrypt(key, value string, recfun func(string) (pstring, error)) (pstring, error) {
+
+	encryptionKey, err := recfun(CONFIG_ENCRYPTION_KEY)
+	if err != nil {
+		return pstring{}, err
+	} else if !encryptionKey.p || encryptionKey.str == "" {
+		return pstring{}, fmt.Errorf("The value of '%s' is encrypted, but no key '%s' is defined.",
+			key, CONFIG_ENCRYPTION_KEY)
+	}
+
+	if plain, err := DecryptValue(encryptionKey.str, value); err != nil {
+		return pstring{}, fmt.Errorf("Can not decrypt the value of '%s': %w", key, err)
+	} else {
+		return pstring{true, plain, encryptionKey.deps, encryptionKey.externals}, nil
+	}
+
+}
+
+func init() {
+
+	Declare(Key{
+		Name:        CONFIG_ENCRYPTION_KEY,
+		Secret:      true,
+		Description: "Base64 encoded AES key used to decrypt the values 'ENC(...)'.",
+	})
+
+}
@@ -9,30 +9,82 @@ import (
 )
 
 func convertEnvVarKey(key string) string {
-	return strings.Replace(strings.ToLower(key), "_", ".", -1)
+	parts := strings.Split(strings.ToLower(key), "__")
+	for i, part := range parts {
+		parts[i] = strings.Replace(part, "_", ".", -1)
+	}
+	return strings.Join(parts, "_")
 }
 
-func ParseEnvVar(envvar []string) map[string]string {
+// ParseEnvVarWith converts the environment variables to configuration entries.
+// If the prefix is not empty, only the variables starting with the prefix are
+// kept, and the prefix is removed. The mappings define explicit configuration
+// keys for some variables, regardless of the prefix.
+func ParseEnvVarWith(envvar []string, prefix string, mappings map[string]string) map[string]string {
+
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix = prefix + "_"
+	}
 
 	env := make(map[string]string, len(envvar))
 
 	for _, e := range envvar {
-		pair := strings.Split(e, "=")
-		env[convertEnvVarKey(pair[0])] = pair[1]
+
+		name, value, _ := strings.Cut(e, "=")
+
+		if key, p := mappings[name]; p {
+			env[key] = value
+		} else if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			env[convertEnvVarKey(name[len(prefix):])] = value
+		}
+
 	}
 
 	return env
 
 }
 
+func ParseEnvVar(envvar []string) map[string]string {
+	return ParseEnvVarWith(envvar, "", nil)
+}
+
 type EnvVarConfigSource ConfigSource
 
-var CONFIG_SOURCE_PRIORITY_ENV_VAR = 0
+var (
+	CONFIG_SOURCE_PRIORITY_ENV_VAR = 0
+	CONFIG_SOURCE_ENV_VAR_PREFIX   = "config.env.prefix"
+	CONFIG_SOURCE_ENV_VAR_MAPPING  = "config.env.mapping"
+)
 
 type EnvVarConfigSourceImpl struct {
 	source map[string]string
 }
 
+// NewEnvVarConfigSourceWith returns a config source of the environment
+// variables, filtered by the prefix and the mappings.
+func NewEnvVarConfigSourceWith(environ []string, prefix string, mappings map[string]string) *EnvVarConfigSourceImpl {
+	return &EnvVarConfigSourceImpl{ParseEnvVarWith(environ, prefix, mappings)}
+}
+
+// NewEnvVarConfigSource returns a config source of the environment variables,
+// filtered by the prefix and the mappings defined in the default configuration
+// (see Set).
+func NewEnvVarConfigSource(environ []string) *EnvVarConfigSourceImpl {
+
+	defaults := getDefaultConfigMap()
+
+	mappingPrefix := CONFIG_SOURCE_ENV_VAR_MAPPING + "."
+	mappings := make(map[string]string)
+	for key, target := range defaults {
+		if strings.HasPrefix(key, mappingPrefix) {
+			mappings[key[len(mappingPrefix):]] = target
+		}
+	}
+
+	return NewEnvVarConfigSourceWith(environ, defaults[CONFIG_SOURCE_ENV_VAR_PREFIX], mappings)
+
+}
+
 func (self *EnvVarConfigSourceImpl) GetPriority() int {
 	return CONFIG_SOURCE_PRIORITY_ENV_VAR
 }
@@ -54,9 +106,15 @@ func (self *EnvVarConfigSourceImpl) String() string {
 
 func init() {
 
-	ioc.DefaultPutNamed("Env var config source (default)",
-		&EnvVarConfigSourceImpl{ParseEnvVar(os.Environ())},
-		func(EnvVarConfigSource) {})
+	Declare(Key{
+		Name:        CONFIG_SOURCE_ENV_VAR_PREFIX,
+		Description: "Prefix of the environment variables imported in the configuration.",
+	})
+
+	ioc.DefaultPutNamedFactory("Env var config source (default)",
+		func() (*EnvVarConfigSourceImpl, error) {
+			return NewEnvVarConfigSource(os.Environ()), nil
+		}, func(EnvVarConfigSource) {})
 
 	ioc.PutNamedFactory("Env var config source (promoter)",
 		func(v EnvVarConfigSource) (ConfigSource, error) { return v, nil })
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -24,4 +25,81 @@ var _ = Describe("Envvar config source", func() {
 
 	})
 
+	It("should keep equal signs in values", func() {
+
+		env := ParseEnvVar([]string{"EQUATION=E=mc2"})
+
+		Expect(env).Should(HaveKeyWithValue("equation", "E=mc2"))
+
+	})
+
+	It("should convert double underscores", func() {
+
+		env := ParseEnvVar([]string{"MAX__SIZE_LIMIT=10"})
+
+		Expect(env).Should(HaveKeyWithValue("max_size.limit", "10"))
+
+	})
+
+	It("should filter by prefix and map variables", func() {
+
+		env := ParseEnvVarWith([]string{
+			"MYAPP_SERVER_PORT=8080",
+			"PATH=/usr/bin",
+			"DATABASE_URL=postgres://localhost",
+		}, "MYAPP", map[string]string{"DATABASE_URL": "db.url"})
+
+		Expect(env).Should(Equal(map[string]string{
+			"server.port": "8080",
+			"db.url":      "postgres://localhost",
+		}))
+
+	})
+
+	It("should only expose the filtered variables", func() {
+
+		source := NewEnvVarConfigSourceWith([]string{
+			"MYAPP_NAME=Pigs",
+			"AWS_SECRET_ACCESS_KEY=hunter2",
+		}, "MYAPP", nil)
+
+		Expect(source.Json().String()).To(Equal(`{"name":"Pigs"}`))
+
+	})
+
+	Describe("Config source", func() {
+
+		var backup map[string]string
+
+		BeforeEach(func() {
+			backup = BackupDefault()
+		})
+
+		AfterEach(func() {
+			RestoreDefault(backup)
+		})
+
+		It("should use configured prefix and mappings", func() {
+
+			SetMap(map[string]string{
+				CONFIG_SOURCE_ENV_VAR_PREFIX:              "MYAPP_",
+				CONFIG_SOURCE_ENV_VAR_MAPPING + ".DB_URL": "db.url",
+			})
+
+			ioc.TestPut(NewEnvVarConfigSource([]string{
+				"MYAPP_NAME=Pigs",
+				"HOME=/home/pigs",
+				"DB_URL=mysql://localhost",
+			}), func(ConfigSource) {})
+
+			ioc.CallInjected(func(config Configuration) {
+				Expect(config.Get("name")).To(Equal("Pigs"))
+				Expect(config.Get("db.url")).To(Equal("mysql://localhost"))
+				Expect(config.HasKey("home")).To(BeFalse())
+			})
+
+		})
+
+	})
+
 })
@@ -2,7 +2,11 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/b-charles/pigs/ioc"
@@ -10,26 +14,27 @@ import (
 	"github.com/spf13/afero"
 )
 
-func mergeIn(config MutableConfig, path string, json json.JsonNode) {
+// flattenJson converts a json document to configuration entries.
+func flattenJson(path string, json json.JsonNode, set func(string, string)) {
 
 	if json.IsString() {
 
-		config.Set(path, json.AsString())
+		set(path, json.AsString())
 
 	} else if json.IsFloat() {
 
-		config.Set(path, fmt.Sprintf("%f", json.AsFloat()))
+		set(path, fmt.Sprintf("%f", json.AsFloat()))
 
 	} else if json.IsInt() {
 
-		config.Set(path, fmt.Sprintf("%d", json.AsInt()))
+		set(path, fmt.Sprintf("%d", json.AsInt()))
 
 	} else if json.IsBool() {
 
 		if json.AsBool() {
-			config.Set(path, "true")
+			set(path, "true")
 		} else {
-			config.Set(path, "false")
+			set(path, "false")
 		}
 
 	} else if json.IsObject() {
@@ -41,7 +46,7 @@ func mergeIn(config MutableConfig, path string, json json.JsonNode) {
 				sub = fmt.Sprintf("%s.%s", path, key)
 			}
 
-			mergeIn(config, sub, json.GetMember(key))
+			flattenJson(sub, json.GetMember(key), set)
 
 		}
 
@@ -57,33 +62,118 @@ func mergeIn(config MutableConfig, path string, json json.JsonNode) {
 				sub = fmt.Sprintf("%d", i)
 			}
 
-			mergeIn(config, sub, json.GetElement(i))
+			flattenJson(sub, json.GetElement(i), set)
 
 		}
 
 	} else { // null
 
-		config.Set(path, "null")
+		set(path, "null")
 
 	}
 
 }
 
+func mergeIn(config MutableConfig, path string, json json.JsonNode) {
+	flattenJson(path, json, config.Set)
+}
+
 type JsonFilesConfigSource ConfigSource
 
 var (
 	CONFIG_SOURCE_PRIORITY_JSON_FILES = 200
 	CONFIG_SOURCE_JSON_PREFIX         = "config.json"
+	CONFIG_SOURCE_JSON_OPTIONAL       = "optional:"
+	CONFIG_PROFILES                   = "config.profiles"
 )
 
 type JsonFilesConfigSourceImpl struct {
 	fs afero.Fs
 }
 
+func NewJsonFilesConfigSource(fs afero.Fs) *JsonFilesConfigSourceImpl {
+	return &JsonFilesConfigSourceImpl{fs}
+}
+
 func (self *JsonFilesConfigSourceImpl) GetPriority() int {
 	return CONFIG_SOURCE_PRIORITY_JSON_FILES
 }
 
+// profiles returns the active profiles, defined as a comma separated list.
+func profiles(config MutableConfig) ([]string, error) {
+
+	value, _, err := config.Lookup(CONFIG_PROFILES)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]string, 0)
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles, nil
+
+}
+
+// profilePath returns the path of the profile specific variant of a file (e.g.
+// 'application-dev.json' for 'application.json').
+func profilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), profile, ext)
+}
+
+// expandPath returns the files corresponding to the given path: the json files
+// of a directory or the files matching a glob pattern, in lexicographical order,
+// or the path itself.
+func (self *JsonFilesConfigSourceImpl) expandPath(path string) ([]string, bool, error) {
+
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := afero.Glob(self.fs, path)
+		sort.Strings(matches)
+		return matches, true, err
+	}
+
+	if info, err := self.fs.Stat(path); err != nil || !info.IsDir() {
+		return []string{path}, false, nil
+	}
+
+	infos, err := afero.ReadDir(self.fs, path)
+	if err != nil {
+		return nil, true, err
+	}
+
+	files := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
+			files = append(files, filepath.Join(path, info.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, true, nil
+
+}
+
+// loadFile merges the content of the given json file. Returns false if the
+// file doesn't exist.
+func (self *JsonFilesConfigSourceImpl) loadFile(config MutableConfig, path string) (bool, error) {
+
+	if b, err := afero.ReadFile(self.fs, path); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("Can't read the json file '%s': %w", path, err)
+	} else if json, err := json.Parse(bytes.NewReader(b)); err != nil {
+		return false, fmt.Errorf("Can't parse the json file '%s': %w", path, err)
+	} else {
+		mergeIn(config, "", json)
+		return true, nil
+	}
+
+}
+
 func (self *JsonFilesConfigSourceImpl) LoadEnv(config MutableConfig) error {
 
 	keys := []string{}
@@ -92,21 +182,44 @@ func (self *JsonFilesConfigSourceImpl) LoadEnv(config MutableConfig) error {
 			keys = append(keys, key)
 		}
 	}
+	sort.Strings(keys)
+
+	profiles, err := profiles(config)
+	if err != nil {
+		return err
+	}
 
 	for _, key := range keys {
 
-		if path, _, err := config.Lookup(key); err != nil {
+		path, _, err := config.Lookup(key)
+		if err != nil {
+			return err
+		}
+
+		optional := strings.HasPrefix(path, CONFIG_SOURCE_JSON_OPTIONAL)
+		path = strings.TrimPrefix(path, CONFIG_SOURCE_JSON_OPTIONAL)
 
+		files, multiple, err := self.expandPath(path)
+		if err != nil {
 			return err
+		} else if len(files) == 0 && !optional {
+			return fmt.Errorf("No json file found for '%s' (defined by '%s').", path, key)
+		}
 
-		} else {
+		for _, file := range files {
 
-			if b, err := afero.ReadFile(self.fs, path); err != nil {
-				continue
-			} else if json, err := json.Parse(bytes.NewReader(b)); err != nil {
+			if found, err := self.loadFile(config, file); err != nil {
 				return err
-			} else {
-				mergeIn(config, "", json)
+			} else if !found && !optional {
+				return fmt.Errorf("The json file '%s' (defined by '%s') doesn't exist.", file, key)
+			}
+
+			if !multiple {
+				for _, profile := range profiles {
+					if _, err := self.loadFile(config, profilePath(file, profile)); err != nil {
+						return err
+					}
+				}
 			}
 
 		}
@@ -119,11 +232,16 @@ func (self *JsonFilesConfigSourceImpl) LoadEnv(config MutableConfig) error {
 
 func init() {
 
-	Set(CONFIG_SOURCE_JSON_PREFIX, "application.json")
+	Set(CONFIG_SOURCE_JSON_PREFIX, CONFIG_SOURCE_JSON_OPTIONAL+"application.json")
+
+	Declare(Key{
+		Name:        CONFIG_PROFILES,
+		Description: "Comma separated list of the active profiles.",
+	})
 
 	ioc.DefaultPutNamedFactory("Json config source (default)",
 		func(fs afero.Fs) (*JsonFilesConfigSourceImpl, error) {
-			return &JsonFilesConfigSourceImpl{fs}, nil
+			return NewJsonFilesConfigSource(fs), nil
 		}, func(JsonFilesConfigSource) {})
 
 	ioc.PutNamedFactory("Json config source (promoter)",
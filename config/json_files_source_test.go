@@ -51,4 +51,54 @@ var _ = Describe("Json", func() {
 
 	})
 
+	It("should layer profile specific files", func() {
+
+		Set(CONFIG_PROFILES, "dev, local")
+
+		appFs := afero.NewMemMapFs()
+		afero.WriteFile(appFs, "application.json", []byte(`{"db":{"host":"prod","port":"5432"}}`), 0644)
+		afero.WriteFile(appFs, "application-dev.json", []byte(`{"db":{"host":"dev"}}`), 0644)
+		afero.WriteFile(appFs, "application-local.json", []byte(`{"db":{"port":"5433"}}`), 0644)
+
+		ioc.TestPut(appFs, func(afero.Fs) {})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("db.host")).To(Equal("dev"))
+			Expect(config.Get("db.port")).To(Equal("5433"))
+		})
+
+	})
+
+	It("should load directories in order", func() {
+
+		Set("config.json.dir", "conf.d")
+
+		appFs := afero.NewMemMapFs()
+		afero.WriteFile(appFs, "conf.d/20-override.json", []byte(`{"song":"Paint It Black"}`), 0644)
+		afero.WriteFile(appFs, "conf.d/10-base.json", []byte(`{"song":"Angie","band":"Rolling Stones"}`), 0644)
+		afero.WriteFile(appFs, "conf.d/README.md", []byte(`Not a json`), 0644)
+
+		ioc.TestPut(appFs, func(afero.Fs) {})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("song")).To(Equal("Paint It Black"))
+			Expect(config.Get("band")).To(Equal("Rolling Stones"))
+		})
+
+	})
+
+	It("should fail for missing required files", func() {
+
+		Set("config.json.required", "missing.json")
+		Set("config.json.optional", "optional:missing-too.json")
+
+		_, err := CreateConfiguration([]ConfigSource{
+			NewJsonFilesConfigSource(afero.NewMemMapFs()),
+		}, nil, NewSchema())
+
+		Expect(err).To(MatchError(ContainSubstring("'missing.json'")))
+		Expect(err).ToNot(MatchError(ContainSubstring("missing-too.json")))
+
+	})
+
 })
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Merge strategies
+ */
+
+// MergeStrategy defines how the list or the map defined by a key in a source is
+// merged with the values of the sources of lower priority.
+type MergeStrategy string
+
+const (
+	// MergeReplace removes all the items defined by the lower sources.
+	MergeReplace MergeStrategy = "replace"
+	// MergeAppend adds the items after the items of the lower sources.
+	MergeAppend MergeStrategy = "append"
+	// MergeMerge overrides the items with the same index or key, and keeps the
+	// others.
+	MergeMerge MergeStrategy = "merge"
+)
+
+var (
+	CONFIG_MERGE         = "config.merge"
+	LIST_SEPARATOR       = ","
+	validMergeStrategies = map[MergeStrategy]bool{MergeReplace: true, MergeAppend: true, MergeMerge: true}
+)
+
+// newLayer returns an empty configuration which records the values of one
+// source, and reads the values already merged.
+func (self *configImpl) newLayer(origin string) *configImpl {
+	return &configImpl{
+		mutable:      true,
+		parent:       self,
+		aliases:      self.aliases,
+		oldKeys:      self.oldKeys,
+		deprecations: self.deprecations,
+		origin:       origin,
+		resolvers:    self.resolvers,
+	}
+}
+
+// mergeStrategies returns the merge strategy of each list or map key: the
+// strategies defined by the keys 'config.merge.<key>' take precedence over the
+// ones of the declared keys. Declared lists are replaced by default.
+func mergeStrategies(config *configImpl, schema *Schema) (map[string]MergeStrategy, error) {
+
+	strategies := make(map[string]MergeStrategy)
+
+	for _, key := range schema.Keys() {
+		if key.Merge != "" {
+			strategies[key.Name] = key.Merge
+		} else if key.typ() == TypeList {
+			strategies[key.Name] = MergeReplace
+		}
+	}
+
+	prefix := CONFIG_MERGE + "."
+	for key, value := range config.rawMap() {
+		if strings.HasPrefix(key, prefix) {
+			strategy := MergeStrategy(strings.ToLower(strings.TrimSpace(value)))
+			if !validMergeStrategies[strategy] {
+				return nil, fmt.Errorf("Unknown merge strategy '%s' for '%s', expected '%s', '%s' or '%s'.",
+					value, key, MergeReplace, MergeAppend, MergeMerge)
+			}
+			strategies[key[len(prefix):]] = strategy
+		}
+	}
+
+	return strategies, nil
+
+}
+
+// childIndex returns the index of a list item key (e.g. 2 for 'servers.2' or
+// 'servers.2.host'), or false if the key is not a list item of the parent key.
+func childIndex(parent, key string) (int, string, bool) {
+
+	if !strings.HasPrefix(key, parent+".") {
+		return 0, "", false
+	}
+
+	index, rest, _ := strings.Cut(key[len(parent)+1:], ".")
+	if i, err := strconv.Atoi(index); err != nil || i < 0 {
+		return 0, "", false
+	} else {
+		return i, rest, true
+	}
+
+}
+
+// splitList converts a value defined directly for a list key (e.g.
+// 'servers=a,b') to list items ('servers.0=a', 'servers.1=b').
+func splitList(values map[string]string, key string, separator string) {
+
+	value, p := values[key]
+	if !p {
+		return
+	}
+
+	for k := range values {
+		if strings.HasPrefix(k, key+".") {
+			return
+		}
+	}
+
+	delete(values, key)
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	for i, item := range strings.Split(value, separator) {
+		values[fmt.Sprintf("%s.%d", key, i)] = strings.TrimSpace(item)
+	}
+
+}
+
+// deleteTree removes a key and all its children.
+func (self *configImpl) deleteTree(key string) {
+	self.raws.Range(func(k, v any) bool {
+		if k == key || strings.HasPrefix(k.(string), key+".") {
+			self.raws.Delete(k)
+			self.origins.Delete(k)
+		}
+		return true
+	})
+}
+
+// appendList renumbers the list items of the layer after the items already
+// defined.
+func (self *configImpl) appendList(values map[string]string, key string) {
+
+	next := 0
+	self.raws.Range(func(k, v any) bool {
+		if i, _, ok := childIndex(key, k.(string)); ok && i >= next {
+			next = i + 1
+		}
+		return true
+	})
+
+	found := make(map[int]bool)
+	indexes := make([]int, 0)
+	for k := range values {
+		if i, _, ok := childIndex(key, k); ok && !found[i] {
+			found[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	renumbered := make(map[int]int, len(indexes))
+	for rank, i := range indexes {
+		renumbered[i] = next + rank
+	}
+
+	moved := make(map[string]string)
+	for k, v := range values {
+		if i, rest, ok := childIndex(key, k); ok {
+			delete(values, k)
+			newKey := fmt.Sprintf("%s.%d", key, renumbered[i])
+			if rest != "" {
+				newKey = newKey + "." + rest
+			}
+			moved[newKey] = v
+		}
+	}
+	for k, v := range moved {
+		values[k] = v
+	}
+
+}
+
+// merge integrates the values of a layer, with the merge strategies of the
+// list and map keys.
+func (self *configImpl) merge(layer *configImpl, schema *Schema) error {
+
+	strategies, err := mergeStrategies(layer, schema)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	layer.raws.Range(func(k, v any) bool {
+		values[k.(string)] = v.(string)
+		return true
+	})
+
+	keys := make([]string, 0, len(strategies))
+	for key := range strategies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+
+		splitList(values, key, schema.separator(key))
+
+		defined := false
+		for k := range values {
+			if k == key || strings.HasPrefix(k, key+".") {
+				defined = true
+				break
+			}
+		}
+		if !defined {
+			continue
+		}
+
+		switch strategies[key] {
+		case MergeReplace:
+			self.deleteTree(key)
+		case MergeAppend:
+			self.appendList(values, key)
+		}
+
+	}
+
+	self.origin = layer.origin
+	for k, v := range values {
+		self.Set(k, v)
+	}
+
+	return nil
+
+}
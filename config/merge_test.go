@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+
+	var (
+		backup       map[string]string
+		backupSchema map[string]Key
+	)
+
+	BeforeEach(func() {
+		backup = BackupDefault()
+		backupSchema = BackupSchema()
+	})
+
+	AfterEach(func() {
+		RestoreDefault(backup)
+		RestoreSchema(backupSchema)
+	})
+
+	It("should split and replace declared lists", func() {
+
+		Declare(Key{Name: "beatles", Type: TypeList, Default: "John, Paul, George, Pete"})
+		Test("beatles", "John,Paul,George,Ringo")
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("beatles.3")).To(Equal("Ringo"))
+			Expect(config.HasKey("beatles")).To(BeFalse())
+		})
+
+	})
+
+	It("should replace lists from a higher source", func() {
+
+		Declare(Key{Name: "stones", Type: TypeList})
+		TestMap(map[string]string{"stones.0": "Mick", "stones.1": "Keith", "stones.2": "Brian"})
+		TestMap(map[string]string{"stones.0": "Ronnie"})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("stones.0")).To(Equal("Ronnie"))
+			Expect(config.HasKey("stones.1")).To(BeFalse())
+		})
+
+	})
+
+	It("should append lists", func() {
+
+		Set(CONFIG_MERGE+".plugins", "append")
+		Set("plugins", "auth, cache")
+		TestMap(map[string]string{"plugins.0.name": "metrics", "plugins.0.port": "9090"})
+		Test("plugins", "tracing")
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("plugins.0")).To(Equal("auth"))
+			Expect(config.Get("plugins.1")).To(Equal("cache"))
+			Expect(config.Get("plugins.2.name")).To(Equal("metrics"))
+			Expect(config.Get("plugins.2.port")).To(Equal("9090"))
+			Expect(config.Get("plugins.3")).To(Equal("tracing"))
+		})
+
+	})
+
+	It("should merge or replace maps", func() {
+
+		Declare(Key{Name: "labels", Merge: MergeReplace})
+		Set("labels.team", "core")
+		Set("labels.env", "dev")
+		Set("annotations.team", "core")
+		Set("annotations.env", "dev")
+		TestMap(map[string]string{"labels.env": "prod", "annotations.env": "prod"})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.HasKey("labels.team")).To(BeFalse())
+			Expect(config.Get("labels.env")).To(Equal("prod"))
+			Expect(config.Get("annotations.team")).To(Equal("core"))
+			Expect(config.Get("annotations.env")).To(Equal("prod"))
+		})
+
+	})
+
+	It("should reject unknown strategies", func() {
+
+		_, err := CreateConfiguration([]ConfigSource{&SimpleConfigSource{0, map[string]string{
+			CONFIG_MERGE + ".hosts": "shuffle",
+		}}}, nil, NewSchema())
+		Expect(err).To(MatchError(ContainSubstring("Unknown merge strategy 'shuffle'")))
+
+	})
+
+})
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/b-charles/pigs/ioc"
+	"github.com/spf13/afero"
+)
+
+/*
+ * PlaceholderResolver
+ */
+
+type PlaceholderResolver interface {
+	GetPrefix() string
+	Resolve(string) (string, bool, error)
+}
+
+/*
+ * Env var
+ */
+
+type EnvPlaceholderResolver PlaceholderResolver
+
+type EnvPlaceholderResolverImpl struct{}
+
+func (self *EnvPlaceholderResolverImpl) GetPrefix() string {
+	return CONFIG_PLACEHOLDERS_ENV
+}
+
+func (self *EnvPlaceholderResolverImpl) Resolve(name string) (string, bool, error) {
+	value, p := lookupEnv(strings.TrimSpace(name))
+	return value, p, nil
+}
+
+/*
+ * File
+ */
+
+type FilePlaceholderResolver PlaceholderResolver
+
+var CONFIG_PLACEHOLDERS_FILE = "file"
+
+type FilePlaceholderResolverImpl struct {
+	fs afero.Fs
+}
+
+func (self *FilePlaceholderResolverImpl) GetPrefix() string {
+	return CONFIG_PLACEHOLDERS_FILE
+}
+
+func (self *FilePlaceholderResolverImpl) Resolve(path string) (string, bool, error) {
+	if b, err := afero.ReadFile(self.fs, strings.TrimSpace(path)); errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	} else {
+		return strings.TrimRight(string(b), "\r\n"), true, nil
+	}
+}
+
+/*
+ * Base64
+ */
+
+type Base64PlaceholderResolver PlaceholderResolver
+
+var CONFIG_PLACEHOLDERS_BASE64 = "base64"
+
+type Base64PlaceholderResolverImpl struct{}
+
+func (self *Base64PlaceholderResolverImpl) GetPrefix() string {
+	return CONFIG_PLACEHOLDERS_BASE64
+}
+
+func (self *Base64PlaceholderResolverImpl) Resolve(encoded string) (string, bool, error) {
+	if b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded)); err != nil {
+		return "", false, err
+	} else {
+		return string(b), true, nil
+	}
+}
+
+func init() {
+
+	ioc.DefaultPutNamed("Env var placeholder resolver (default)",
+		&EnvPlaceholderResolverImpl{}, func(EnvPlaceholderResolver) {})
+
+	ioc.PutNamedFactory("Env var placeholder resolver (promoter)",
+		func(r EnvPlaceholderResolver) (PlaceholderResolver, error) { return r, nil })
+
+	ioc.DefaultPutNamedFactory("File placeholder resolver (default)",
+		func(fs afero.Fs) (*FilePlaceholderResolverImpl, error) {
+			return &FilePlaceholderResolverImpl{fs}, nil
+		}, func(FilePlaceholderResolver) {})
+
+	ioc.PutNamedFactory("File placeholder resolver (promoter)",
+		func(r FilePlaceholderResolver) (PlaceholderResolver, error) { return r, nil })
+
+	ioc.DefaultPutNamed("Base64 placeholder resolver (default)",
+		&Base64PlaceholderResolverImpl{}, func(Base64PlaceholderResolver) {})
+
+	ioc.PutNamedFactory("Base64 placeholder resolver (promoter)",
+		func(r Base64PlaceholderResolver) (PlaceholderResolver, error) { return r, nil })
+
+}
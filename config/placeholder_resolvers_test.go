@@ -0,0 +1,94 @@
+package config_test
+
+import (
+	"strings"
+
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type UpperPlaceholderResolver struct{}
+
+func (self *UpperPlaceholderResolver) GetPrefix() string {
+	return "upper"
+}
+
+func (self *UpperPlaceholderResolver) Resolve(value string) (string, bool, error) {
+	return strings.ToUpper(value), true, nil
+}
+
+var _ = Describe("Placeholder resolvers", func() {
+
+	It("should read secret files", func() {
+
+		appFs := afero.NewMemMapFs()
+		afero.WriteFile(appFs, "/run/secrets/db_password", []byte("Open Sesame\n"), 0600)
+
+		ioc.TestPut(appFs, func(afero.Fs) {})
+
+		TestMap(map[string]string{
+			"db.password": "${file:/run/secrets/db_password}",
+			"db.user":     "${file:/run/secrets/db_user:Ali Baba}",
+		})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("db.password")).To(Equal("Open Sesame"))
+			Expect(config.Get("db.user")).To(Equal("Ali Baba"))
+		})
+
+	})
+
+	It("should mask the resolved values in the dumps", func() {
+
+		appFs := afero.NewMemMapFs()
+		afero.WriteFile(appFs, "/run/secrets/db_credentials", []byte("ali:sesame\n"), 0600)
+
+		ioc.TestPut(appFs, func(afero.Fs) {})
+
+		TestMap(map[string]string{
+			"db.credentials": "${file:/run/secrets/db_credentials}",
+			"db.url":         "postgres://${db.credentials}@cave",
+			"db.user":        "${file:/run/secrets/db_user:Ali Baba}",
+		})
+
+		ioc.CallInjected(func(dump *Dump) {
+			props := dump.Properties()
+			Expect(props).To(ContainSubstring("db.credentials=******\n"))
+			Expect(props).To(ContainSubstring("db.url=******\n"))
+			Expect(props).To(ContainSubstring("db.user=Ali Baba\n"))
+		})
+
+	})
+
+	It("should decode base64 values", func() {
+
+		TestMap(map[string]string{
+			"encoded": "VGhlIEJlYXRsZXM=",
+			"band":    "${base64:${encoded}}",
+			"invalid": "${base64:!!!}",
+		})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("band")).To(Equal("The Beatles"))
+			_, _, err := config.Lookup("invalid")
+			Expect(err).To(HaveOccurred())
+		})
+
+	})
+
+	It("should use injected resolvers", func() {
+
+		ioc.TestPut(&UpperPlaceholderResolver{}, func(PlaceholderResolver) {})
+
+		Test("shout", "${upper:help!}")
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("shout")).To(Equal("HELP!"))
+		})
+
+	})
+
+})
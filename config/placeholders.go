@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+ * Placeholders
+ */
+
+var (
+	CONFIG_PLACEHOLDERS_STRICT = "config.placeholders.strict"
+	CONFIG_PLACEHOLDERS_ENV    = "env"
+)
+
+var lookupEnv = os.LookupEnv
+
+// pstring is a resolved value, with the keys and the prefixes of the
+// PlaceholderResolvers used by its placeholders (directly or not).
+type pstring struct {
+	p         bool
+	str       string
+	deps      []string
+	externals []string
+}
+
+// interpolator resolves the placeholders of the value of one key.
+type interpolator struct {
+	key       string
+	strict    bool
+	recfun    func(string) (pstring, error)
+	resolvers map[string]PlaceholderResolver
+	deps      []string
+	externals []string
+}
+
+func (self *configImpl) resolveValue(strict bool, key string, recfun func(string) (pstring, error)) (pstring, error) {
+
+	if raw, p := self.load(key); !p {
+
+		return pstring{}, nil
+
+	} else if IsEncrypted(raw) {
+
+		return self.decrypt(key, raw, recfun)
+
+	} else {
+
+		interpolator := &interpolator{key: key, strict: strict, recfun: recfun, resolvers: self.resolvers}
+		if value, err := interpolator.interpolate(raw); err != nil {
+			return pstring{}, err
+		} else {
+			return pstring{true, value, interpolator.deps, interpolator.externals}, nil
+		}
+
+	}
+
+}
+
+// closingBrace returns the index of the brace closing the one at the given
+// index, or -1 if the braces are unbalanced.
+func closingBrace(value string, open int) int {
+
+	depth := 0
+	for i := open; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+
+}
+
+// splitExpression splits the expression on the first colon which is not
+// nested in a placeholder.
+func splitExpression(expr string) (string, string, bool) {
+
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ':':
+			if depth == 0 {
+				return expr[:i], expr[i+1:], true
+			}
+		}
+	}
+
+	return expr, "", false
+
+}
+
+// interpolate replaces each placeholder of the value by its resolution.
+func (self *interpolator) interpolate(value string) (string, error) {
+
+	var b strings.Builder
+
+	for i := 0; i < len(value); {
+
+		if strings.HasPrefix(value[i:], "$${") {
+
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				b.WriteString(value[i+1:])
+				break
+			}
+
+			b.WriteString(value[i+1 : end+1])
+			i = end + 1
+
+		} else if strings.HasPrefix(value[i:], "${") {
+
+			end := closingBrace(value, i+1)
+			if end < 0 {
+				b.WriteString(value[i:])
+				break
+			}
+
+			if resolved, p, err := self.evaluate(value[i+2 : end]); err != nil {
+				return "", err
+			} else if p {
+				b.WriteString(resolved)
+			} else if self.strict {
+				return "", fmt.Errorf("Unresolved placeholder '%s' in the value of '%s'.", value[i:end+1], self.key)
+			} else {
+				b.WriteString(value[i : end+1])
+			}
+
+			i = end + 1
+
+		} else {
+
+			b.WriteByte(value[i])
+			i++
+
+		}
+
+	}
+
+	return b.String(), nil
+
+}
+
+// evaluate resolves the expression of a placeholder: '<key>', '<key>:<default>',
+// '<prefix>:<argument>' or '<prefix>:<argument>:<default>', where '<prefix>'
+// is the prefix of a PlaceholderResolver.
+func (self *interpolator) evaluate(expr string) (string, bool, error) {
+
+	head, fallback, hasFallback := splitExpression(expr)
+
+	name, err := self.interpolate(head)
+	if err != nil {
+		return "", false, err
+	}
+	name = strings.TrimSpace(name)
+
+	if resolver, p := self.resolvers[name]; p && hasFallback {
+
+		head, fallback, hasFallback = splitExpression(fallback)
+
+		if argument, err := self.interpolate(head); err != nil {
+			return "", false, err
+		} else if value, p, err := resolver.Resolve(argument); err != nil {
+			return "", false, fmt.Errorf("Error during resolving '%s:%s' in the value of '%s': %w",
+				name, argument, self.key, err)
+		} else if p {
+			self.externals = append(self.externals, name)
+			return value, true, nil
+		}
+
+	} else if value, err := self.recfun(name); err != nil {
+		return "", false, err
+	} else if value.p {
+		self.deps = append(append(self.deps, name), value.deps...)
+		self.externals = append(self.externals, value.externals...)
+		return value.str, true, nil
+	}
+
+	if hasFallback {
+		value, err := self.interpolate(fallback)
+		return value, err == nil, err
+	}
+
+	return "", false, nil
+
+}
@@ -0,0 +1,412 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-charles/pigs/ioc"
+	"github.com/b-charles/pigs/json"
+	"github.com/benbjohnson/clock"
+	"github.com/spf13/afero"
+)
+
+// RemoteConfigSource is the config source of the remote key-value backend,
+// which also notifies the changes detected by the polling.
+type RemoteConfigSource interface {
+	ConfigSource
+	OnChange(func(map[string]string))
+}
+
+var (
+	CONFIG_SOURCE_PRIORITY_REMOTE = 300
+	CONFIG_SOURCE_REMOTE_URL      = "config.remote.url"
+	CONFIG_SOURCE_REMOTE_PREFIX   = "config.remote.prefix"
+	CONFIG_SOURCE_REMOTE_CACHE    = "config.remote.cache"
+	CONFIG_SOURCE_REMOTE_TIMEOUT  = "config.remote.timeout"
+	CONFIG_SOURCE_REMOTE_INTERVAL = "config.remote.interval"
+)
+
+// decodeRemote converts the body returned by the key-value backend to
+// configuration entries. The body can be a Consul-like list of entries (with a
+// 'Key' and a base64 encoded 'Value'), where the keys are stripped of the prefix
+// and the '/' are replaced by '.', or a Json object.
+func decodeRemote(body []byte, prefix string) (map[string]string, error) {
+
+	node, err := json.ParseBytes(body)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+
+	if node.IsObject() {
+		flattenJson("", node, func(k, v string) { values[k] = v })
+		return values, nil
+	}
+
+	if !node.IsArray() {
+		return nil, fmt.Errorf("Unexpected remote configuration: %v", node)
+	}
+
+	for i := 0; i < node.GetLen(); i++ {
+
+		entry := node.GetElement(i)
+		if !entry.IsObject() || !entry.GetMember("Key").IsString() {
+			return nil, fmt.Errorf("Unexpected remote configuration entry: %v", entry)
+		}
+
+		key := strings.Trim(strings.TrimPrefix(entry.GetMember("Key").AsString(), prefix), "/")
+		value := entry.GetMember("Value")
+		if key == "" || !value.IsString() {
+			continue
+		}
+
+		if decoded, err := base64.StdEncoding.DecodeString(value.AsString()); err != nil {
+			return nil, fmt.Errorf("Can not decode the value of '%s': %w", key, err)
+		} else {
+			values[strings.ReplaceAll(key, "/", ".")] = string(decoded)
+		}
+
+	}
+
+	return values, nil
+
+}
+
+type remoteSettings struct {
+	url      string
+	prefix   string
+	cache    string
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func readRemoteSettings(config MutableConfig) (*remoteSettings, error) {
+
+	settings := &remoteSettings{}
+
+	for key, value := range map[string]*string{
+		CONFIG_SOURCE_REMOTE_URL:    &settings.url,
+		CONFIG_SOURCE_REMOTE_PREFIX: &settings.prefix,
+		CONFIG_SOURCE_REMOTE_CACHE:  &settings.cache,
+	} {
+		if v, _, err := config.Lookup(key); err != nil {
+			return nil, err
+		} else {
+			*value = v
+		}
+	}
+
+	for key, value := range map[string]*time.Duration{
+		CONFIG_SOURCE_REMOTE_TIMEOUT:  &settings.timeout,
+		CONFIG_SOURCE_REMOTE_INTERVAL: &settings.interval,
+	} {
+		if v, _, err := config.Lookup(key); err != nil {
+			return nil, err
+		} else if d, err := time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("Invalid value '%s' for '%s': %w", v, key, err)
+		} else {
+			*value = d
+		}
+	}
+
+	return settings, nil
+
+}
+
+// RemoteConfigSourceImpl loads the configuration from an HTTP key-value
+// backend. The last loaded configuration can be cached in a file, used if the
+// backend is unreachable at boot. If an interval is defined, the backend is
+// polled and the listeners are notified of each change; the already created
+// Configuration is not modified. The polling errors are recorded and notified
+// to the error listeners.
+type RemoteConfigSourceImpl struct {
+	fs             afero.Fs
+	clock          clock.Clock
+	mu             sync.Mutex
+	settings       *remoteSettings
+	values         map[string]string
+	listeners      []func(map[string]string)
+	errorListeners []func(error)
+	err            error
+	stop           chan struct{}
+}
+
+func NewRemoteConfigSource(fs afero.Fs, clock clock.Clock) *RemoteConfigSourceImpl {
+	return &RemoteConfigSourceImpl{
+		fs:             fs,
+		clock:          clock,
+		values:         map[string]string{},
+		listeners:      []func(map[string]string){},
+		errorListeners: []func(error){},
+	}
+}
+
+func (self *RemoteConfigSourceImpl) GetPriority() int {
+	return CONFIG_SOURCE_PRIORITY_REMOTE
+}
+
+func (self *RemoteConfigSourceImpl) fetch(settings *remoteSettings) (map[string]string, []byte, error) {
+
+	client := &http.Client{Timeout: settings.timeout}
+
+	resp, err := client.Get(settings.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string]string{}, []byte("{}"), nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Unexpected status '%s'.", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values, err := decodeRemote(body, settings.prefix)
+	return values, body, err
+
+}
+
+func (self *RemoteConfigSourceImpl) writeCache(settings *remoteSettings, body []byte) error {
+	if settings.cache == "" {
+		return nil
+	}
+	return afero.WriteFile(self.fs, settings.cache, body, 0600)
+}
+
+func (self *RemoteConfigSourceImpl) readCache(settings *remoteSettings) (map[string]string, error) {
+	if settings.cache == "" {
+		return nil, fmt.Errorf("No cache file defined.")
+	} else if body, err := afero.ReadFile(self.fs, settings.cache); err != nil {
+		return nil, err
+	} else {
+		return decodeRemote(body, settings.prefix)
+	}
+}
+
+func (self *RemoteConfigSourceImpl) LoadEnv(config MutableConfig) error {
+
+	settings, err := readRemoteSettings(config)
+	if err != nil {
+		return err
+	} else if settings.url == "" {
+		return nil
+	}
+
+	// A source already loaded with the same settings reuses its last values,
+	// kept up to date by the refreshes: the configuration can be rebuilt by a
+	// change listener without querying the backend again.
+	self.mu.Lock()
+	loaded := self.settings != nil && *self.settings == *settings
+	values := self.values
+	self.mu.Unlock()
+	if loaded {
+		for k, v := range values {
+			config.Set(k, v)
+		}
+		return nil
+	}
+
+	values, body, err := self.fetch(settings)
+	if err != nil {
+		if cached, cacheErr := self.readCache(settings); cacheErr != nil {
+			return fmt.Errorf("Can not load the remote configuration from '%s' (%v), and can not read the cache: %w",
+				settings.url, err, cacheErr)
+		} else {
+			values = cached
+		}
+	} else if err := self.writeCache(settings, body); err != nil {
+		return fmt.Errorf("Can not write the remote configuration cache '%s': %w", settings.cache, err)
+	}
+
+	for k, v := range values {
+		config.Set(k, v)
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.settings = settings
+	self.values = values
+
+	if settings.interval > 0 && self.stop == nil {
+		self.stop = make(chan struct{})
+		go self.poll(self.clock.Ticker(settings.interval), self.stop)
+	}
+
+	return nil
+
+}
+
+func (self *RemoteConfigSourceImpl) poll(ticker *clock.Ticker, stop chan struct{}) {
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			self.Refresh()
+		}
+	}
+
+}
+
+// Refresh loads the configuration from the backend, and notifies the
+// listeners if it has changed. An error is recorded (see LastError) and
+// notified to the error listeners.
+func (self *RemoteConfigSourceImpl) Refresh() error {
+
+	self.mu.Lock()
+	settings := self.settings
+	self.mu.Unlock()
+
+	if settings == nil {
+		return nil
+	}
+
+	values, body, err := self.fetch(settings)
+	if err == nil {
+		err = self.update(settings, values, body)
+	}
+	if err != nil {
+		err = fmt.Errorf("Can not refresh the remote configuration from '%s': %w", settings.url, err)
+	}
+
+	self.mu.Lock()
+	self.err = err
+	errorListeners := self.errorListeners
+	self.mu.Unlock()
+
+	if err != nil {
+		for _, listener := range errorListeners {
+			listener(err)
+		}
+	}
+
+	return err
+
+}
+
+// update records the new values, and notifies the listeners if they have
+// changed.
+func (self *RemoteConfigSourceImpl) update(settings *remoteSettings, values map[string]string, body []byte) error {
+
+	self.mu.Lock()
+	changed := !reflect.DeepEqual(values, self.values)
+	if changed {
+		self.values = values
+	}
+	listeners := self.listeners
+	self.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	for _, listener := range listeners {
+		listener(values)
+	}
+
+	return self.writeCache(settings, body)
+
+}
+
+// OnChange records a listener, called with the new values each time the
+// polling detects a change.
+func (self *RemoteConfigSourceImpl) OnChange(listener func(map[string]string)) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.listeners = append(self.listeners, listener)
+}
+
+// OnError records a listener, called with the error each time the polling
+// fails.
+func (self *RemoteConfigSourceImpl) OnError(listener func(error)) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.errorListeners = append(self.errorListeners, listener)
+}
+
+// LastError returns the error of the last refresh, or nil if it succeeded.
+func (self *RemoteConfigSourceImpl) LastError() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.err
+}
+
+// Values returns the last loaded values.
+func (self *RemoteConfigSourceImpl) Values() map[string]string {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.values
+}
+
+func (self *RemoteConfigSourceImpl) Close() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.stop != nil {
+		close(self.stop)
+		self.stop = nil
+	}
+	return nil
+}
+
+func (self *RemoteConfigSourceImpl) Json() json.JsonNode {
+	return json.NewJsonObjectStrings(self.Values())
+}
+
+func (self *RemoteConfigSourceImpl) String() string {
+	return self.Json().String()
+}
+
+func init() {
+
+	DeclareKeys(
+		Key{
+			Name:        CONFIG_SOURCE_REMOTE_URL,
+			Description: "Url of the remote key-value configuration backend.",
+		},
+		Key{
+			Name:        CONFIG_SOURCE_REMOTE_PREFIX,
+			Description: "Prefix of the keys in the remote configuration backend.",
+		},
+		Key{
+			Name:        CONFIG_SOURCE_REMOTE_CACHE,
+			Description: "Cache file of the remote configuration, used if the backend is unreachable.",
+		},
+		Key{
+			Name:        CONFIG_SOURCE_REMOTE_TIMEOUT,
+			Type:        TypeDuration,
+			Default:     "5s",
+			Description: "Timeout of the requests to the remote configuration backend.",
+		},
+		Key{
+			Name:        CONFIG_SOURCE_REMOTE_INTERVAL,
+			Type:        TypeDuration,
+			Default:     "0s",
+			Description: "Polling interval of the remote configuration backend (no polling if 0).",
+		},
+	)
+
+	ioc.DefaultPutNamedFactory("Remote config source (default)",
+		func(fs afero.Fs, clock clock.Clock) (*RemoteConfigSourceImpl, error) {
+			return NewRemoteConfigSource(fs, clock), nil
+		}, func(RemoteConfigSource) {})
+
+	ioc.PutNamedFactory("Remote config source (promoter)",
+		func(v RemoteConfigSource) (ConfigSource, error) { return v, nil })
+
+}
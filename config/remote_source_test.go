@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	"github.com/benbjohnson/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Remote", func() {
+
+	var (
+		backup map[string]string
+		body   atomic.Value
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		backup = BackupDefault()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body.Load().(string)))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		RestoreDefault(backup)
+	})
+
+	It("should load a Consul-like key-value list", func() {
+
+		body.Store(`[
+			{"Key":"app/", "Value":null},
+			{"Key":"app/db/host", "Value":"bG9jYWxob3N0"},
+			{"Key":"app/db/port", "Value":"NTQzMg=="}
+		]`)
+
+		Set(CONFIG_SOURCE_REMOTE_URL, server.URL)
+		Set(CONFIG_SOURCE_REMOTE_PREFIX, "app/")
+		ioc.TestPut(afero.NewMemMapFs(), func(afero.Fs) {})
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("db.host")).To(Equal("localhost"))
+			Expect(config.Get("db.port")).To(Equal("5432"))
+		})
+
+	})
+
+	It("should load a json object and fall back on the cache", func() {
+
+		body.Store(`{"band":{"name":"Queen","singer":"Freddie"}}`)
+
+		appFs := afero.NewMemMapFs()
+
+		Set(CONFIG_SOURCE_REMOTE_URL, server.URL)
+		Set(CONFIG_SOURCE_REMOTE_CACHE, "remote-cache.json")
+
+		config, err := CreateConfiguration([]ConfigSource{
+			NewRemoteConfigSource(appFs, clock.New()),
+		}, nil, NewSchema())
+		Expect(err).To(Succeed())
+		Expect(config.Get("band.singer")).To(Equal("Freddie"))
+
+		server.Close()
+
+		config, err = CreateConfiguration([]ConfigSource{
+			NewRemoteConfigSource(appFs, clock.New()),
+		}, nil, NewSchema())
+		Expect(err).To(Succeed())
+		Expect(config.Get("band.name")).To(Equal("Queen"))
+
+	})
+
+	It("should fail if the backend is unreachable without cache", func() {
+
+		server.Close()
+		Set(CONFIG_SOURCE_REMOTE_URL, server.URL)
+
+		_, err := CreateConfiguration([]ConfigSource{
+			NewRemoteConfigSource(afero.NewMemMapFs(), clock.New()),
+		}, nil, NewSchema())
+		Expect(err).To(MatchError(ContainSubstring("Can not load the remote configuration")))
+
+	})
+
+	It("should reuse the last values once loaded", func() {
+
+		body.Store(`{"song":"Bohemian Rhapsody"}`)
+		Set(CONFIG_SOURCE_REMOTE_URL, server.URL)
+
+		source := NewRemoteConfigSource(afero.NewMemMapFs(), clock.New())
+
+		_, err := CreateConfiguration([]ConfigSource{source}, nil, NewSchema())
+		Expect(err).To(Succeed())
+
+		server.Close()
+
+		config, err := CreateConfiguration([]ConfigSource{source}, nil, NewSchema())
+		Expect(err).To(Succeed())
+		Expect(config.Get("song")).To(Equal("Bohemian Rhapsody"))
+
+	})
+
+	It("should notify the changes", func() {
+
+		body.Store(`{"song":"Bohemian Rhapsody"}`)
+
+		mock := clock.NewMock()
+		source := NewRemoteConfigSource(afero.NewMemMapFs(), mock)
+		defer source.Close()
+
+		changes := make(chan map[string]string, 1)
+		source.OnChange(func(values map[string]string) { changes <- values })
+
+		_, err := CreateConfiguration([]ConfigSource{
+			&SimpleConfigSource{0, map[string]string{
+				CONFIG_SOURCE_REMOTE_URL:      server.URL,
+				CONFIG_SOURCE_REMOTE_INTERVAL: "1m",
+			}},
+			source,
+		}, nil, NewSchema())
+		Expect(err).To(Succeed())
+
+		body.Store(`{"song":"Under Pressure"}`)
+		mock.Add(time.Minute)
+
+		Eventually(changes).Should(Receive(HaveKeyWithValue("song", "Under Pressure")))
+		Expect(source.Values()).To(HaveKeyWithValue("song", "Under Pressure"))
+
+	})
+
+	It("should record and notify the polling errors", func() {
+
+		body.Store(`{"song":"Bohemian Rhapsody"}`)
+
+		mock := clock.NewMock()
+		source := NewRemoteConfigSource(afero.NewMemMapFs(), mock)
+
+		errs := make(chan error, 1)
+		source.OnError(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+
+		_, err := CreateConfiguration([]ConfigSource{
+			&SimpleConfigSource{0, map[string]string{
+				CONFIG_SOURCE_REMOTE_URL:      server.URL,
+				CONFIG_SOURCE_REMOTE_INTERVAL: "1m",
+			}},
+			source,
+		}, nil, NewSchema())
+		Expect(err).To(Succeed())
+
+		body.Store(`not json`)
+		go source.Close()
+		mock.Add(time.Minute)
+		source.Close()
+
+		Expect(source.Refresh()).NotTo(Succeed())
+		Eventually(errs).Should(Receive(MatchError(ContainSubstring("Can not refresh the remote configuration"))))
+		Expect(source.LastError()).To(HaveOccurred())
+		Expect(source.Values()).To(HaveKeyWithValue("song", "Bohemian Rhapsody"))
+
+		body.Store(`{"song":"Bohemian Rhapsody"}`)
+		Expect(source.Refresh()).To(Succeed())
+		Expect(source.LastError()).To(Succeed())
+
+	})
+
+})
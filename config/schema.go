@@ -0,0 +1,357 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b-charles/pigs/ioc"
+	"github.com/b-charles/pigs/json"
+)
+
+/*
+ * Key descriptors
+ */
+
+type KeyType string
+
+const (
+	TypeString   KeyType = "string"
+	TypeInt      KeyType = "int"
+	TypeFloat    KeyType = "float"
+	TypeBool     KeyType = "bool"
+	TypeDuration KeyType = "duration"
+	TypeList     KeyType = "list"
+)
+
+var keyTypeValidators = map[KeyType]func(string) error{
+	TypeString: func(string) error { return nil },
+	TypeInt: func(value string) error {
+		_, err := strconv.Atoi(value)
+		return err
+	},
+	TypeFloat: func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	},
+	TypeBool: func(value string) error {
+		_, err := strconv.ParseBool(value)
+		return err
+	},
+	TypeDuration: func(value string) error {
+		_, err := time.ParseDuration(value)
+		return err
+	},
+	TypeList: func(string) error { return nil },
+}
+
+// Key describes a configuration key accepted by the application. Only the name
+// is mandatory, the type is 'string' by default. If a default value is given,
+// it's recorded like with the function Set. The deprecated alias is an old name
+// of the key, still accepted. The short name is used for command line
+// arguments. The value of a secret key is masked in the configuration dumps.
+// The merge strategy defines how a list or a map is merged across the sources.
+type Key struct {
+	Name        string
+	Type        KeyType
+	Default     string
+	Description string
+	Required    bool
+	Deprecated  string
+	Short       rune
+	Secret      bool
+	Merge       MergeStrategy
+	Separator   string
+}
+
+func (self Key) typ() KeyType {
+	if self.Type == "" {
+		return TypeString
+	}
+	return self.Type
+}
+
+func (self Key) separator() string {
+	if self.Separator == "" {
+		return LIST_SEPARATOR
+	}
+	return self.Separator
+}
+
+func (self Key) Json() json.JsonNode {
+
+	b := json.NewJsonBuilder()
+	b.SetString("name", self.Name)
+	b.SetString("type", string(self.typ()))
+	if self.Default != "" {
+		b.SetString("default", self.Default)
+	}
+	if self.Description != "" {
+		b.SetString("description", self.Description)
+	}
+	b.SetBool("required", self.Required)
+	if self.Deprecated != "" {
+		b.SetString("deprecated", self.Deprecated)
+	}
+	if self.Short != 0 {
+		b.SetString("short", string(self.Short))
+	}
+	if self.Secret {
+		b.SetBool("secret", true)
+	}
+	if self.Merge != "" {
+		b.SetString("merge", string(self.Merge))
+	}
+	if self.Separator != "" {
+		b.SetString("separator", self.Separator)
+	}
+
+	return b.Build()
+
+}
+
+func (self Key) String() string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s (%s", self.Name, self.typ())
+	if self.Default != "" {
+		fmt.Fprintf(&b, ", default: %s", self.Default)
+	}
+	if self.Separator != "" {
+		fmt.Fprintf(&b, ", separator: %s", self.Separator)
+	}
+	if self.Required {
+		b.WriteString(", required")
+	}
+	if self.Secret {
+		b.WriteString(", secret")
+	}
+	b.WriteString(")")
+
+	if self.Description != "" {
+		fmt.Fprintf(&b, "\n    %s", self.Description)
+	}
+	if self.Deprecated != "" {
+		fmt.Fprintf(&b, "\n    Deprecated alias: %s", self.Deprecated)
+	}
+
+	return b.String()
+
+}
+
+/*
+ * Declaration
+ */
+
+var (
+	HELP_KEY     = Key{Name: "help", Type: TypeBool, Description: "Show this help.", Short: 'h'}
+	declaredKeys = map[string]Key{HELP_KEY.Name: HELP_KEY}
+)
+
+func declaredKey(name string) (Key, bool) {
+	key, p := declaredKeys[name]
+	return key, p
+}
+
+func declaredShort(short rune) (Key, bool) {
+	for _, key := range declaredKeys {
+		if key.Short == short {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+func Declare(key Key) {
+
+	if key.Name == "" {
+		panic(fmt.Sprintf("A declared key should have a name: %v.", key))
+	}
+	if old, p := declaredKeys[key.Name]; p {
+		panic(fmt.Sprintf("The key '%s' can't be declared twice ('%v' and '%v').", key.Name, old, key))
+	}
+	if _, p := keyTypeValidators[key.typ()]; !p {
+		panic(fmt.Sprintf("Unknown type '%s' for the key '%s'.", key.Type, key.Name))
+	}
+	if key.Merge != "" && !validMergeStrategies[key.Merge] {
+		panic(fmt.Sprintf("Unknown merge strategy '%s' for the key '%s'.", key.Merge, key.Name))
+	}
+	if key.Short != 0 {
+		if old, p := declaredShort(key.Short); p {
+			panic(fmt.Sprintf("The short name '%c' can't be used for '%s' and '%s'.", key.Short, old.Name, key.Name))
+		}
+	}
+
+	if key.Default != "" {
+		Set(key.Name, key.Default)
+	}
+
+	declaredKeys[key.Name] = key
+
+}
+
+func DeclareKeys(keys ...Key) {
+	for _, key := range keys {
+		Declare(key)
+	}
+}
+
+func BackupSchema() map[string]Key {
+	backup := make(map[string]Key, len(declaredKeys))
+	for k, v := range declaredKeys {
+		backup[k] = v
+	}
+	return backup
+}
+
+func RestoreSchema(backup map[string]Key) {
+	for k := range declaredKeys {
+		delete(declaredKeys, k)
+	}
+	for k, v := range backup {
+		declaredKeys[k] = v
+	}
+}
+
+/*
+ * Schema
+ */
+
+// Schema is the set of declared keys and aliases. The deprecation hook is
+// notified when a deprecated key is used.
+type Schema struct {
+	keys         []Key
+	aliases      map[string]string
+	deprecations *DeprecationHook
+}
+
+func NewSchema() *Schema {
+
+	keys := make([]Key, 0, len(declaredKeys))
+	for _, key := range declaredKeys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	aliases := BackupAliases()
+	for _, key := range keys {
+		if key.Deprecated != "" {
+			aliases[key.Deprecated] = key.Name
+		}
+	}
+
+	return &Schema{keys, aliases, nil}
+
+}
+
+func (self *Schema) WithDeprecationHook(hook *DeprecationHook) *Schema {
+	self.deprecations = hook
+	return self
+}
+
+func (self *Schema) Keys() []Key {
+	return self.keys
+}
+
+// separator returns the separator of the items of a list key given directly as
+// one value.
+func (self *Schema) separator(name string) string {
+	for _, key := range self.keys {
+		if key.Name == name {
+			return key.separator()
+		}
+	}
+	return LIST_SEPARATOR
+}
+
+// Aliases returns the deprecated keys, mapped to their new keys.
+func (self *Schema) Aliases() map[string]string {
+	return self.aliases
+}
+
+// Validate checks the configuration against the declared keys.
+func (self *Schema) Validate(config Configuration) error {
+	return self.validate(config, func(string) bool { return true })
+}
+
+// validate checks the configuration against the declared keys accepted by the
+// filter.
+func (self *Schema) validate(config Configuration, filter func(string) bool) error {
+
+	errs := make([]error, 0)
+
+	keys := config.Keys()
+	hasChildren := func(name string) bool {
+		for _, k := range keys {
+			if strings.HasPrefix(k, name+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, key := range self.keys {
+
+		if !filter(key.Name) {
+			continue
+		}
+
+		if key.typ() == TypeList {
+			if key.Required && !config.HasKey(key.Name) && !hasChildren(key.Name) {
+				errs = append(errs, fmt.Errorf("The key '%s' is required.", key.Name))
+			}
+		} else if value, p, err := config.Lookup(key.Name); err != nil {
+			errs = append(errs, fmt.Errorf("The key '%s' can not be resolved: %w", key.Name, err))
+		} else if !p {
+			if key.Required {
+				errs = append(errs, fmt.Errorf("The key '%s' is required.", key.Name))
+			}
+		} else if err := keyTypeValidators[key.typ()](value); err != nil {
+			errs = append(errs, fmt.Errorf("The value '%s' of the key '%s' is not a valid %s: %w",
+				value, key.Name, key.typ(), err))
+		}
+
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+
+}
+
+func (self *Schema) Json() json.JsonNode {
+	return json.NewJsonArrayMapped(self.keys, func(key Key) json.JsonNode {
+		return key.Json()
+	})
+}
+
+func (self *Schema) String() string {
+
+	var b strings.Builder
+	for i, key := range self.keys {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(key.String())
+	}
+
+	return b.String()
+
+}
+
+func init() {
+
+	ioc.PutNamedFactory("Configuration schema",
+		func(hook *DeprecationHook) (*Schema, error) {
+			return NewSchema().WithDeprecationHook(hook), nil
+		})
+
+}
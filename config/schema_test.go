@@ -0,0 +1,113 @@
+package config_test
+
+import (
+	. "github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schema", func() {
+
+	var (
+		backup       map[string]string
+		backupSchema map[string]Key
+	)
+
+	BeforeEach(func() {
+		backup = BackupDefault()
+		backupSchema = BackupSchema()
+	})
+
+	AfterEach(func() {
+		RestoreDefault(backup)
+		RestoreSchema(backupSchema)
+	})
+
+	It("should record default values", func() {
+
+		Declare(Key{Name: "pink.floyd", Default: "Money"})
+		ioc.TestPut("ioc test flag")
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("pink.floyd")).To(Equal("Money"))
+		})
+
+	})
+
+	It("should check required keys and types", func() {
+
+		DeclareKeys(
+			Key{Name: "the.clash", Required: true},
+			Key{Name: "the.ramones", Type: TypeInt},
+		)
+
+		_, err := CreateConfiguration([]ConfigSource{&SimpleConfigSource{0, map[string]string{
+			"the.ramones": "Blitzkrieg Bop",
+		}}}, nil, NewSchema())
+		Expect(err).To(MatchError(ContainSubstring("'the.clash' is required")))
+		Expect(err).To(MatchError(ContainSubstring("'the.ramones' is not a valid int")))
+
+	})
+
+	It("should validate the overridden values", func() {
+
+		Declare(Key{Name: "the.ramones", Type: TypeInt})
+
+		config, err := CreateConfiguration([]ConfigSource{&SimpleConfigSource{0, map[string]string{
+			"the.ramones": "1976",
+		}}}, nil, NewSchema())
+		Expect(err).To(Succeed())
+
+		_, err = config.WithOverrides(map[string]string{"the.ramones": "Blitzkrieg Bop"})
+		Expect(err).To(MatchError(ContainSubstring("'the.ramones' is not a valid int")))
+
+		derived, err := config.WithOverrides(map[string]string{"the.ramones": "1977"})
+		Expect(err).To(Succeed())
+		Expect(derived.Get("the.ramones")).To(Equal("1977"))
+
+	})
+
+	It("should validate the values depending on the overridden values", func() {
+
+		Declare(Key{Name: "the.kinks", Type: TypeInt})
+
+		config, err := CreateConfiguration([]ConfigSource{&SimpleConfigSource{0, map[string]string{
+			"the.kinks": "${year}",
+			"year":      "1964",
+		}}}, nil, NewSchema())
+		Expect(err).To(Succeed())
+
+		_, err = config.WithOverrides(map[string]string{"year": "You Really Got Me"})
+		Expect(err).To(MatchError(ContainSubstring("'the.kinks' is not a valid int")))
+
+		derived, err := config.WithOverrides(map[string]string{"year": "1965"})
+		Expect(err).To(Succeed())
+		Expect(derived.Get("the.kinks")).To(Equal("1965"))
+
+	})
+
+	It("should accept deprecated aliases", func() {
+
+		Declare(Key{Name: "the.who", Default: "My Generation", Deprecated: "who"})
+		Test("who", "Baba O'Riley")
+
+		ioc.CallInjected(func(config Configuration) {
+			Expect(config.Get("the.who")).To(Equal("Baba O'Riley"))
+		})
+
+	})
+
+	It("should render the reference", func() {
+
+		Declare(Key{Name: "the.doors", Type: TypeDuration, Default: "7m", Description: "Light My Fire"})
+		ioc.TestPut("ioc test flag")
+
+		ioc.CallInjected(func(schema *Schema) {
+			Expect(schema.String()).To(ContainSubstring("the.doors (duration, default: 7m)\n    Light My Fire"))
+			Expect(schema.Json().String()).To(ContainSubstring(`"name":"the.doors"`))
+		})
+
+	})
+
+})
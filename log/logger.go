@@ -208,8 +208,20 @@ func init() {
 	config.Set(fmt.Sprintf("%s.%s", ROOT_CONFIG, DEFAULT_LOGGER_NAME), fmt.Sprintf("${%s}", ROOT_CONFIG))
 
 	ioc.DefaultPutNamedFactory(fmt.Sprintf("Logger '%s'", DEFAULT_LOGGER_NAME),
-		func(loggerFactory LoggerFactory) (Logger, error) {
-			return loggerFactory.NewLogger(DEFAULT_LOGGER_NAME), nil
+		func(loggerFactory LoggerFactory, deprecations *config.DeprecationHook) (Logger, error) {
+
+			logger := loggerFactory.NewLogger(DEFAULT_LOGGER_NAME)
+
+			deprecations.Subscribe(func(oldKey, newKey string) {
+				logger.Warn().
+					SetString("message", "Deprecated configuration key").
+					SetString("deprecated", oldKey).
+					SetString("replacement", newKey).
+					Log()
+			})
+
+			return logger, nil
+
 		}, func(Logger) {})
 
 }
@@ -123,4 +123,26 @@ var _ = Describe("Log", func() {
 
 	})
 
+	It("should warn about deprecated configuration keys.", func() {
+
+		backup := config.BackupAliases()
+		defer config.RestoreAliases(backup)
+
+		config.Alias("band.name", "band.title")
+		config.Test("band.name", "Pixies")
+
+		ioc.CallInjected(func(conf config.Configuration, logger Logger, appender *BytesAppender) {
+
+			Expect(conf.Get("band.title")).To(Equal("Pixies"))
+
+			out := appender.String()
+
+			Expect(out).To(ContainSubstring("\"level\":\"WARN\""))
+			Expect(out).To(ContainSubstring("\"deprecated\":\"band.name\""))
+			Expect(out).To(ContainSubstring("\"replacement\":\"band.title\""))
+
+		})
+
+	})
+
 })
@@ -0,0 +1,67 @@
+package smartconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/b-charles/pigs/ioc"
+)
+
+// Get configures a new value of type T from the given node, with the
+// configurer of the configuration tree. An error is returned if T can not be
+// configured.
+func Get[T any](config NavConfig, opts ...Option) (T, error) {
+
+	var value T
+
+	smartConfigurer, err := boundConfigurer(config)
+	if err != nil {
+		return value, err
+	}
+
+	if err := smartConfigurer.configure(config, reflect.ValueOf(&value).Elem(), opts); err != nil {
+		return value, fmt.Errorf("Can not configure %v from '%s': %w",
+			reflect.TypeOf((*T)(nil)).Elem(), config.Path(), err)
+	}
+
+	return value, nil
+
+}
+
+// createBound returns a factory of a value of type T, configured from the
+// root path.
+func createBound[T any](root string, opts []Option) func(*SmartConfigurer) (T, error) {
+	return func(configurer *SmartConfigurer) (T, error) {
+		var value T
+		err := configurer.Configure(root, &value, opts...)
+		return value, err
+	}
+}
+
+func DefaultBind[T any](root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.DefaultPutFactory(createBound[T](root, opts))
+}
+
+func DefaultBindNamed[T any](name string, root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.DefaultPutNamedFactory(name, createBound[T](root, opts))
+}
+
+func Bind[T any](root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.PutFactory(createBound[T](root, opts))
+}
+
+func BindNamed[T any](name string, root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.PutNamedFactory(name, createBound[T](root, opts))
+}
+
+func TestBind[T any](root string, opts ...Option) {
+	ioc.TestPutFactory(createBound[T](root, opts))
+}
+
+func TestBindNamed[T any](name string, root string, opts ...Option) {
+	ioc.TestPutNamedFactory(name, createBound[T](root, opts))
+}
@@ -0,0 +1,91 @@
+package smartconfig_test
+
+import (
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type cache_config struct {
+	Size int           `default:"100"`
+	Ttl  time.Duration `required:"true"`
+}
+
+var _ = Describe("Bind", func() {
+
+	It("should inject a bound value", func() {
+
+		TestBind[cache_config]("cache")
+		config.Test("cache.ttl", "1m")
+
+		ioc.CallInjected(func(injected cache_config) {
+			Expect(injected).To(Equal(cache_config{Size: 100, Ttl: time.Minute}))
+		})
+
+	})
+
+	It("should inject a bound pointer", func() {
+
+		TestBind[*cache_config]("cache")
+		config.TestMap(map[string]string{
+			"cache.size": "10",
+			"cache.ttl":  "1s",
+		})
+
+		ioc.CallInjected(func(injected *cache_config) {
+			Expect(injected).To(Equal(&cache_config{Size: 10, Ttl: time.Second}))
+		})
+
+	})
+
+	It("should fail to inject an invalid bound value", func() {
+
+		TestBind[*cache_config]("cache", Strict())
+		config.TestMap(map[string]string{
+			"cache.ttl":  "1s",
+			"cache.mode": "lru",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*cache_config) {})
+		Expect(err).To(MatchError(ContainSubstring("cache.mode")))
+
+	})
+
+	It("should fail to inject an unconfigurable type", func() {
+
+		TestBind[chan int]("cache")
+		config.TestMap(map[string]string{})
+
+		err := ioc.ErroneousCallInjected(func(chan int) {})
+		Expect(err).To(MatchError(ContainSubstring("No configurer found")))
+
+	})
+
+	It("should decode a node", func() {
+
+		config.TestMap(map[string]string{
+			"caches.users.ttl":  "1m",
+			"caches.tokens.ttl": "1h",
+		})
+
+		ioc.CallInjected(func(nav NavConfig) {
+
+			caches, err := Get[map[string]cache_config](nav.Child("caches"))
+			Expect(err).To(Succeed())
+			Expect(caches).To(Equal(map[string]cache_config{
+				"users":  {Size: 100, Ttl: time.Minute},
+				"tokens": {Size: 100, Ttl: time.Hour},
+			}))
+
+			_, err = Get[func()](nav.Child("caches"))
+			Expect(err).To(MatchError(ContainSubstring("Can not configure func() from 'caches'")))
+
+		})
+
+	})
+
+})
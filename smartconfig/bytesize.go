@@ -0,0 +1,100 @@
+package smartconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes, configurable with human readable values like
+// "512", "10kB" or "1.5GiB".
+type ByteSize int64
+
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KB,
+	"kb":  KB,
+	"m":   MB,
+	"mb":  MB,
+	"g":   GB,
+	"gb":  GB,
+	"t":   TB,
+	"tb":  TB,
+	"p":   PB,
+	"pb":  PB,
+	"ki":  KiB,
+	"kib": KiB,
+	"mi":  MiB,
+	"mib": MiB,
+	"gi":  GiB,
+	"gib": GiB,
+	"ti":  TiB,
+	"tib": TiB,
+	"pi":  PiB,
+	"pib": PiB,
+}
+
+var byteSizeRegexp = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+func ParseByteSize(value string) (ByteSize, error) {
+
+	match := byteSizeRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("Value '%s' is not a valid byte size.", value)
+	}
+
+	unit, ok := byteSizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("Unknown byte size unit '%s' in '%s'.", match[2], value)
+	}
+
+	if n, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+		if n > math.MaxInt64/int64(unit) {
+			return 0, fmt.Errorf("Value '%s' overflows a byte size.", value)
+		}
+		return ByteSize(n) * unit, nil
+	} else if f, err := strconv.ParseFloat(match[1], 64); err != nil {
+		return 0, fmt.Errorf("Value '%s' is not a valid byte size: %w", value, err)
+	} else if size := f * float64(unit); size >= math.MaxInt64 {
+		return 0, fmt.Errorf("Value '%s' overflows a byte size.", value)
+	} else {
+		return ByteSize(size), nil
+	}
+
+}
+
+func (self ByteSize) String() string {
+
+	units := []struct {
+		size ByteSize
+		name string
+	}{{PiB, "PiB"}, {TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}}
+
+	for _, unit := range units {
+		if self != 0 && self%unit.size == 0 {
+			return fmt.Sprintf("%d%s", self/unit.size, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%dB", int64(self))
+
+}
@@ -0,0 +1,156 @@
+package smartconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingKey is the cause of the failure of a required key which is not
+// defined.
+var ErrMissingKey = errors.New("missing required key")
+
+// PathError is the failure of the configuration of one path.
+type PathError struct {
+	Path  string
+	Cause error
+}
+
+func (self *PathError) Error() string {
+	if self.Path == "" {
+		return self.Cause.Error()
+	}
+	return fmt.Sprintf("%s: %v", self.Path, self.Cause)
+}
+
+func (self *PathError) Unwrap() error {
+	return self.Cause
+}
+
+// ConfigurationErrors collects all the failures of a configuration: the values
+// which can not be parsed, the missing required keys, the violations of the
+// validation rules... Its Unwrap method exposes each PathError, and a
+// MissingKeysError and a ValidationError grouping the missing keys and the
+// violations.
+type ConfigurationErrors struct {
+	Errors []*PathError
+}
+
+func (self *ConfigurationErrors) Error() string {
+	messages := make([]string, 0, len(self.Errors))
+	for _, err := range self.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("Invalid configuration (%d errors): %s.", len(self.Errors), strings.Join(messages, "; "))
+}
+
+// Paths returns the paths of the failures, in the order of the configuration.
+func (self *ConfigurationErrors) Paths() []string {
+	paths := make([]string, 0, len(self.Errors))
+	for _, err := range self.Errors {
+		paths = append(paths, err.Path)
+	}
+	return paths
+}
+
+func (self *ConfigurationErrors) Unwrap() []error {
+
+	missing := &MissingKeysError{}
+	invalid := &ValidationError{}
+	for _, err := range self.Errors {
+		var violation Violation
+		if errors.Is(err.Cause, ErrMissingKey) {
+			missing.Paths = append(missing.Paths, err.Path)
+		} else if errors.As(err.Cause, &violation) {
+			invalid.Violations = append(invalid.Violations, violation)
+		}
+	}
+
+	errs := make([]error, 0, len(self.Errors)+2)
+	if len(missing.Paths) > 0 {
+		errs = append(errs, missing)
+	}
+	if len(invalid.Violations) > 0 {
+		errs = append(errs, invalid)
+	}
+	for _, err := range self.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+
+}
+
+// add records the failure of a path. The failures already collected by a
+// ConfigurationErrors, a MissingKeysError or a ValidationError are recorded
+// with their own paths.
+func (self *ConfigurationErrors) add(path string, err error) {
+
+	var (
+		errs    *ConfigurationErrors
+		missing *MissingKeysError
+		invalid *ValidationError
+	)
+
+	switch {
+	case errors.As(err, &errs):
+		self.Errors = append(self.Errors, errs.Errors...)
+	case errors.As(err, &missing):
+		for _, p := range missing.Paths {
+			self.Errors = append(self.Errors, &PathError{p, ErrMissingKey})
+		}
+	case errors.As(err, &invalid):
+		for _, violation := range invalid.Violations {
+			self.Errors = append(self.Errors, &PathError{violation.Path, violation})
+		}
+	default:
+		self.Errors = append(self.Errors, &PathError{path, err})
+	}
+
+}
+
+// err returns the collected failures, or nil.
+func (self *ConfigurationErrors) err() error {
+	if len(self.Errors) == 0 {
+		return nil
+	}
+	return self
+}
+
+// MissingKeysError reports the required configuration keys which are not
+// defined, with their full paths.
+type MissingKeysError struct {
+	Paths []string
+}
+
+func (self *MissingKeysError) Error() string {
+	return fmt.Sprintf("Missing required configuration keys: %s.", strings.Join(self.Paths, ", "))
+}
+
+// Violation is a configured value rejected by a validation rule.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (self Violation) Error() string {
+	return self.Message
+}
+
+// ValidationError reports all the values rejected by the validation tags or by
+// the Validate methods, with their full paths.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (self *ValidationError) Error() string {
+	messages := make([]string, 0, len(self.Violations))
+	for _, violation := range self.Violations {
+		if violation.Path == "" {
+			messages = append(messages, violation.Message)
+		} else {
+			messages = append(messages, fmt.Sprintf("%s: %s", violation.Path, violation.Message))
+		}
+	}
+	return fmt.Sprintf("Invalid configuration: %s.", strings.Join(messages, "; "))
+}
@@ -0,0 +1,71 @@
+package smartconfig_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type backend_config struct {
+	Host    string `required:"true"`
+	Port    int    `max:"65535"`
+	Timeout time.Duration
+}
+
+type cluster_config struct {
+	Name     string `required:"true"`
+	Replicas int
+	Backends []backend_config
+	Weights  map[string]float64
+}
+
+var _ = Describe("Configuration errors", func() {
+
+	It("should collect all the failures", func() {
+
+		TestConfigure("cluster", &cluster_config{})
+		config.TestMap(map[string]string{
+			"cluster.replicas":           "three",
+			"cluster.backends.0.host":    "a",
+			"cluster.backends.0.port":    "70000",
+			"cluster.backends.0.timeout": "1s",
+			"cluster.backends.1.port":    "80",
+			"cluster.backends.1.timeout": "soon",
+			"cluster.weights.a":          "0.5",
+			"cluster.weights.b":          "half",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*cluster_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Paths()).To(Equal([]string{
+			"cluster.name",
+			"cluster.replicas",
+			"cluster.backends.0.port",
+			"cluster.backends.1.host",
+			"cluster.backends.1.timeout",
+			"cluster.weights.b",
+		}))
+
+		Expect(errors.Is(errs.Errors[0], ErrMissingKey)).To(BeTrue())
+		Expect(errs.Errors[2].Cause).To(MatchError("should be at most 65535 (got 70000)"))
+
+		var missing *MissingKeysError
+		Expect(errors.As(err, &missing)).To(BeTrue())
+		Expect(missing.Paths).To(Equal([]string{"cluster.name", "cluster.backends.1.host"}))
+
+		var invalid *ValidationError
+		Expect(errors.As(err, &invalid)).To(BeTrue())
+		Expect(invalid.Violations).To(Equal([]Violation{
+			{"cluster.backends.0.port", "should be at most 65535 (got 70000)"},
+		}))
+
+	})
+
+})
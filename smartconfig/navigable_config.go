@@ -22,11 +22,13 @@ type NavConfig interface {
 }
 
 type navConfigImpl struct {
-	root     *navConfigImpl
-	parent   *navConfigImpl
-	path     string
-	value    string
-	children map[string]*navConfigImpl
+	root       *navConfigImpl
+	parent     *navConfigImpl
+	path       string
+	value      string
+	raw        string
+	children   map[string]*navConfigImpl
+	configurer *SmartConfigurer
 }
 
 func (self *navConfigImpl) Root() NavConfig {
@@ -45,6 +47,11 @@ func (self *navConfigImpl) Value() string {
 	return self.value
 }
 
+// Raw returns the value of the node before the resolution of the placeholders.
+func (self *navConfigImpl) Raw() string {
+	return self.raw
+}
+
 func keyLess(a, b string) bool {
 
 	if a == b {
@@ -93,15 +100,28 @@ func (self *navConfigImpl) child(key string) *navConfigImpl {
 		root:     self.root,
 		parent:   self,
 		path:     path(self.path, key),
-		value:    "",
 		children: map[string]*navConfigImpl{},
 	}
 	self.children[key] = child
 	return child
 }
 
+// lookup returns the child of the key, or a new empty node, detached from the
+// tree, if the child is not defined: the lookups don't modify the tree.
+func (self *navConfigImpl) lookup(key string) *navConfigImpl {
+	if child, pres := self.children[key]; pres {
+		return child
+	}
+	return &navConfigImpl{
+		root:     self.root,
+		parent:   self,
+		path:     path(self.path, key),
+		children: map[string]*navConfigImpl{},
+	}
+}
+
 func (self *navConfigImpl) Child(key string) NavConfig {
-	return self.child(key)
+	return self.lookup(key)
 }
 
 func (self *navConfigImpl) Get(key string) NavConfig {
@@ -111,7 +131,7 @@ func (self *navConfigImpl) Get(key string) NavConfig {
 		if i == 0 && k == "" {
 			conf = conf.root
 		} else {
-			conf = conf.child(k)
+			conf = conf.lookup(k)
 		}
 	}
 
@@ -160,32 +180,138 @@ func (self *navConfigImpl) String() string {
 	return self.Json().String()
 }
 
+// isMissing returns true if the node has no value and no children.
+func isMissing(config NavConfig) bool {
+	return config.Value() == "" && len(config.Keys()) == 0
+}
+
+// valuedNavConfig is a node with a forced value, used for the default values.
+type valuedNavConfig struct {
+	NavConfig
+	value string
+}
+
+func withValue(config NavConfig, value string) NavConfig {
+	return &valuedNavConfig{config, value}
+}
+
+func (self *valuedNavConfig) Value() string {
+	return self.value
+}
+
+// splitItems splits a value of a list, like the configuration does for the
+// list keys given as one value.
+func splitItems(value string) []string {
+	items := []string{}
+	if strings.TrimSpace(value) != "" {
+		for _, item := range strings.Split(value, config.LIST_SEPARATOR) {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
+
+// itemsNavConfig is a node with forced items, used for the default values of
+// the slices.
+type itemsNavConfig struct {
+	NavConfig
+	items []string
+}
+
+func withItems(config NavConfig, value string) NavConfig {
+	return &itemsNavConfig{config, splitItems(value)}
+}
+
+func (self *itemsNavConfig) Keys() []string {
+	keys := make([]string, len(self.items))
+	for i := range self.items {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func (self *itemsNavConfig) Child(key string) NavConfig {
+	if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(self.items) {
+		return withValue(self.NavConfig.Child(key), self.items[i])
+	}
+	return self.NavConfig.Child(key)
+}
+
+func (self *itemsNavConfig) Get(key string) NavConfig {
+	first, rest, nested := strings.Cut(key, ".")
+	if first == "" {
+		return self.NavConfig.Get(key)
+	} else if !nested {
+		return self.Child(first)
+	}
+	return self.Child(first).Get(rest)
+}
+
 func NewNavMap(config config.Configuration) (NavConfig, error) {
 
-	root := &navConfigImpl{nil, nil, "", "", map[string]*navConfigImpl{}}
+	root := &navConfigImpl{children: map[string]*navConfigImpl{}}
 	root.root = root
 
 	for _, key := range config.Keys() {
-		keys := strings.Split(key, ".")
-		insert(keys, config.Get(key), root)
+		value, _, err := config.Lookup(key)
+		if err != nil {
+			return nil, fmt.Errorf("Can not resolve the key '%s': %w", key, err)
+		}
+		raw, _ := config.GetRaw(key)
+		insert(strings.Split(key, "."), value, raw, root)
 	}
 
 	return root, nil
 
 }
 
-func insert(keys []string, value string, navKey *navConfigImpl) {
+func insert(keys []string, value, raw string, navKey *navConfigImpl) {
 
 	if len(keys) == 0 {
 		navKey.value = value
+		navKey.raw = raw
 	} else {
-		insert(keys[1:], value, navKey.child(keys[0]))
+		insert(keys[1:], value, raw, navKey.child(keys[0]))
 	}
 
 }
 
+// bind records the configurer of the tree, used by Lookup and Get.
+func bind(config NavConfig, configurer *SmartConfigurer) {
+	if root, ok := config.Root().(*navConfigImpl); ok && root.configurer == nil {
+		root.configurer = configurer
+	}
+}
+
+// boundConfigurer returns the configurer of the tree of the node.
+func boundConfigurer(config NavConfig) (*SmartConfigurer, error) {
+	if root, ok := config.Root().(*navConfigImpl); ok && root.configurer != nil {
+		return root.configurer, nil
+	}
+	return nil, fmt.Errorf("No configurer is bound to the configuration of '%s'.", config.Path())
+}
+
+// newBoundNavMap returns the tree of the configuration, bound to a configurer
+// with the parsers, the inspectors and the variants.
+func newBoundNavMap(config config.Configuration, parsers []Parser, inspectors []Inspector, variants []*Variant) (NavConfig, error) {
+
+	nav, err := NewNavMap(config)
+	if err != nil {
+		return nil, err
+	}
+
+	configurer, err := newSmartConfigurer(nav, parsers, inspectors, variants)
+	if err != nil {
+		return nil, err
+	}
+	bind(nav, configurer)
+
+	return nav, nil
+
+}
+
 func init() {
 	ioc.PutNamedFactory("Navigable configuration",
-		NewNavMap,
+		newBoundNavMap,
 		func(NavConfig) {})
 }
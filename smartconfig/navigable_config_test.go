@@ -1,6 +1,8 @@
 package smartconfig_test
 
 import (
+	"time"
+
 	"github.com/b-charles/pigs/config"
 	"github.com/b-charles/pigs/ioc"
 	. "github.com/b-charles/pigs/smartconfig"
@@ -71,4 +73,104 @@ var _ = Describe("IOC registration", func() {
 
 	})
 
+	It("should return an error for an unresolvable key", func() {
+
+		config.TestMap(map[string]string{
+			config.CONFIG_PLACEHOLDERS_STRICT: "true",
+			"whoami":                          "I'm ${name}",
+		})
+
+		err := ioc.ErroneousCallInjected(func(NavConfig) {})
+		Expect(err).To(MatchError(ContainSubstring("Can not resolve the key 'whoami'")))
+
+	})
+
+	Describe("Queries", func() {
+
+		BeforeEach(func() {
+			config.TestMap(map[string]string{
+				"db.main.url":       "postgres://${db.host}/main",
+				"db.main.timeout":   "5s",
+				"db.replica.url":    "postgres://${db.host}/replica",
+				"db.replica.weight": "2",
+				"db.host":           "localhost",
+				"hosts.0":           "alpha",
+				"hosts.1":           "beta",
+			})
+		})
+
+		It("should query a path with wildcards", func() {
+
+			ioc.CallInjected(func(config NavConfig) {
+
+				urls := Query(config, "db.*.url")
+				Expect(urls).To(HaveLen(2))
+				Expect(urls[0].Path()).To(Equal("db.main.url"))
+				Expect(urls[0].Value()).To(Equal("postgres://localhost/main"))
+				Expect(urls[1].Path()).To(Equal("db.replica.url"))
+
+				Expect(Query(config.Child("db"), ".hosts.*")).To(HaveLen(2))
+				Expect(Query(config, "db.*.unknown")).To(BeEmpty())
+
+			})
+
+		})
+
+		It("should find the nodes accepted by a predicate", func() {
+
+			ioc.CallInjected(func(config NavConfig) {
+
+				found := Find(config.Child("db"), func(node NavConfig) bool {
+					return node.Value() == "localhost" || len(node.Keys()) == 2
+				})
+
+				paths := make([]string, 0)
+				for _, node := range found {
+					paths = append(paths, node.Path())
+				}
+				Expect(paths).To(Equal([]string{"db.host", "db.main", "db.replica"}))
+
+			})
+
+		})
+
+		It("should parse typed values with the registered parsers", func() {
+
+			ioc.CallInjected(func(config NavConfig) {
+
+				timeout, p, err := Lookup[time.Duration](config.Get("db.main.timeout"))
+				Expect(err).To(Succeed())
+				Expect(p).To(BeTrue())
+				Expect(timeout).To(Equal(5 * time.Second))
+
+				_, p, err = Lookup[int](config.Get("db.main.weight"))
+				Expect(err).To(Succeed())
+				Expect(p).To(BeFalse())
+
+				_, _, err = Lookup[int](config.Get("db.main.url"))
+				Expect(err).To(HaveOccurred())
+
+				_, _, err = Lookup[struct{ Url string }](config.Get("db.main"))
+				Expect(err).To(MatchError(ContainSubstring("not parsed from a single value")))
+
+			})
+
+		})
+
+		It("should export arrays and placeholders in Json", func() {
+
+			ioc.CallInjected(func(config NavConfig) {
+
+				Expect(JsonOf(config.Child("hosts")).String()).To(Equal(`["alpha","beta"]`))
+				Expect(JsonOf(config.Get("db.main")).String()).To(Equal(
+					`{"timeout":"5s","url":"postgres://localhost/main"}`))
+				Expect(RawJsonOf(config.Get("db.main")).String()).To(Equal(
+					`{"timeout":"5s","url":"postgres://${db.host}/main"}`))
+
+			})
+
+		})
+
+	})
+
 })
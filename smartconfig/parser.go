@@ -31,6 +31,7 @@ func parserConfigurer(parser Parser) (*configurer, error) {
 
 	return &configurer{
 		target: typ.Out(0),
+		scalar: true,
 		setter: func(config NavConfig, receiver reflect.Value) error {
 
 			outs := value.Call([]reflect.Value{reflect.ValueOf(config.Value())})
@@ -41,6 +42,8 @@ func parserConfigurer(parser Parser) (*configurer, error) {
 			receiver.Set(outs[0])
 			return nil
 
-		}}, nil
+		},
+		schema: func() *jsonSchema { return scalarSchema(typ.Out(0)) },
+	}, nil
 
 }
@@ -1,8 +1,16 @@
 package smartconfig
 
 import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/b-charles/pigs/config"
 	"github.com/b-charles/pigs/ioc"
 )
 
@@ -10,8 +18,104 @@ import (
 
 type StringParser func(string) (string, error)
 type Float64Parser func(string) (float64, error)
+type Float32Parser func(string) (float32, error)
 type IntParser func(string) (int, error)
+type Int8Parser func(string) (int8, error)
+type Int16Parser func(string) (int16, error)
+type Int32Parser func(string) (int32, error)
+type Int64Parser func(string) (int64, error)
+type UintParser func(string) (uint, error)
+type Uint8Parser func(string) (uint8, error)
+type Uint16Parser func(string) (uint16, error)
+type Uint32Parser func(string) (uint32, error)
+type Uint64Parser func(string) (uint64, error)
 type BoolParser func(string) (bool, error)
+type DurationParser func(string) (time.Duration, error)
+type TimeParser func(string) (time.Time, error)
+type ByteSizeParser func(string) (ByteSize, error)
+type URLParser func(string) (*url.URL, error)
+type IPParser func(string) (net.IP, error)
+type IPNetParser func(string) (*net.IPNet, error)
+type AddrParser func(string) (netip.Addr, error)
+type PrefixParser func(string) (netip.Prefix, error)
+type FileModeParser func(string) (os.FileMode, error)
+
+func intParser[T ~int | ~int8 | ~int16 | ~int32 | ~int64](bits int) func(string) (T, error) {
+	return func(value string) (T, error) {
+		i, err := strconv.ParseInt(value, 10, bits)
+		return T(i), err
+	}
+}
+
+func uintParser[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](bits int) func(string) (T, error) {
+	return func(value string) (T, error) {
+		i, err := strconv.ParseUint(value, 10, bits)
+		return T(i), err
+	}
+}
+
+var (
+	TIME_LAYOUTS = "smartconfig.time.layouts"
+	timeLayouts  = map[string]string{
+		"ANSIC":       time.ANSIC,
+		"UnixDate":    time.UnixDate,
+		"RubyDate":    time.RubyDate,
+		"RFC822":      time.RFC822,
+		"RFC822Z":     time.RFC822Z,
+		"RFC850":      time.RFC850,
+		"RFC1123":     time.RFC1123,
+		"RFC1123Z":    time.RFC1123Z,
+		"RFC3339":     time.RFC3339,
+		"RFC3339Nano": time.RFC3339Nano,
+		"Kitchen":     time.Kitchen,
+		"DateTime":    time.DateTime,
+		"DateOnly":    time.DateOnly,
+		"TimeOnly":    time.TimeOnly,
+	}
+)
+
+// NewTimeParser returns a parser trying each layout in order. A layout can be
+// the name of a layout of the package time (e.g. 'RFC3339').
+func NewTimeParser(layouts []string) func(string) (time.Time, error) {
+
+	resolved := make([]string, len(layouts))
+	for i, layout := range layouts {
+		if named, p := timeLayouts[layout]; p {
+			resolved[i] = named
+		} else {
+			resolved[i] = layout
+		}
+	}
+
+	return func(value string) (time.Time, error) {
+		for _, layout := range resolved {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("The value '%s' doesn't match any time layout %v.", value, layouts)
+	}
+
+}
+
+func parseIP(value string) (net.IP, error) {
+	if ip := net.ParseIP(value); ip == nil {
+		return nil, fmt.Errorf("Invalid IP address '%s'.", value)
+	} else {
+		return ip, nil
+	}
+}
+
+func parseIPNet(value string) (*net.IPNet, error) {
+	_, ipnet, err := net.ParseCIDR(value)
+	return ipnet, err
+}
+
+// parseFileMode parses an octal file mode, like '0644', '644' or '0o644'.
+func parseFileMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(value), "0o"), 8, 32)
+	return os.FileMode(mode), err
+}
 
 func init() {
 
@@ -37,10 +141,147 @@ func init() {
 	ioc.PutNamedFactory("Int parser (promoter)",
 		func(p IntParser) (Parser, error) { return p, nil })
 
+	ioc.DefaultPutNamed("Float32 parser (default)",
+		func(value string) (float32, error) {
+			f, err := strconv.ParseFloat(value, 32)
+			return float32(f), err
+		}, func(Float32Parser) {})
+
+	ioc.PutNamedFactory("Float32 parser (promoter)",
+		func(p Float32Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Int8 parser (default)",
+		intParser[int8](8), func(Int8Parser) {})
+
+	ioc.PutNamedFactory("Int8 parser (promoter)",
+		func(p Int8Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Int16 parser (default)",
+		intParser[int16](16), func(Int16Parser) {})
+
+	ioc.PutNamedFactory("Int16 parser (promoter)",
+		func(p Int16Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Int32 parser (default)",
+		intParser[int32](32), func(Int32Parser) {})
+
+	ioc.PutNamedFactory("Int32 parser (promoter)",
+		func(p Int32Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Int64 parser (default)",
+		intParser[int64](64), func(Int64Parser) {})
+
+	ioc.PutNamedFactory("Int64 parser (promoter)",
+		func(p Int64Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Uint parser (default)",
+		uintParser[uint](strconv.IntSize), func(UintParser) {})
+
+	ioc.PutNamedFactory("Uint parser (promoter)",
+		func(p UintParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Uint8 parser (default)",
+		uintParser[uint8](8), func(Uint8Parser) {})
+
+	ioc.PutNamedFactory("Uint8 parser (promoter)",
+		func(p Uint8Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Uint16 parser (default)",
+		uintParser[uint16](16), func(Uint16Parser) {})
+
+	ioc.PutNamedFactory("Uint16 parser (promoter)",
+		func(p Uint16Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Uint32 parser (default)",
+		uintParser[uint32](32), func(Uint32Parser) {})
+
+	ioc.PutNamedFactory("Uint32 parser (promoter)",
+		func(p Uint32Parser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Uint64 parser (default)",
+		uintParser[uint64](64), func(Uint64Parser) {})
+
+	ioc.PutNamedFactory("Uint64 parser (promoter)",
+		func(p Uint64Parser) (Parser, error) { return p, nil })
+
 	ioc.DefaultPutNamed("Bool parser (default)",
 		strconv.ParseBool, func(BoolParser) {})
 
 	ioc.PutNamedFactory("Bool parser (promoter)",
 		func(p BoolParser) (Parser, error) { return p, nil })
 
+	ioc.DefaultPutNamed("Duration parser (default)",
+		time.ParseDuration, func(DurationParser) {})
+
+	ioc.PutNamedFactory("Duration parser (promoter)",
+		func(p DurationParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Byte size parser (default)",
+		ParseByteSize, func(ByteSizeParser) {})
+
+	ioc.PutNamedFactory("Byte size parser (promoter)",
+		func(p ByteSizeParser) (Parser, error) { return p, nil })
+
+	config.Declare(config.Key{
+		Name:        TIME_LAYOUTS,
+		Type:        config.TypeList,
+		Default:     "RFC3339|DateTime|DateOnly",
+		Description: "Layouts used to parse the times, tried in order.",
+		Separator:   "|",
+	})
+
+	ioc.DefaultPutNamedFactory("Time parser (default)",
+		func(configuration config.Configuration) (TimeParser, error) {
+			layouts := make([]string, 0)
+			for i := 0; ; i++ {
+				layout, p, err := configuration.Lookup(fmt.Sprintf("%s.%d", TIME_LAYOUTS, i))
+				if err != nil {
+					return nil, err
+				} else if !p {
+					break
+				}
+				layouts = append(layouts, layout)
+			}
+			return NewTimeParser(layouts), nil
+		}, func(TimeParser) {})
+
+	ioc.PutNamedFactory("Time parser (promoter)",
+		func(p TimeParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("URL parser (default)",
+		url.Parse, func(URLParser) {})
+
+	ioc.PutNamedFactory("URL parser (promoter)",
+		func(p URLParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("IP parser (default)",
+		parseIP, func(IPParser) {})
+
+	ioc.PutNamedFactory("IP parser (promoter)",
+		func(p IPParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("IP network parser (default)",
+		parseIPNet, func(IPNetParser) {})
+
+	ioc.PutNamedFactory("IP network parser (promoter)",
+		func(p IPNetParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Addr parser (default)",
+		netip.ParseAddr, func(AddrParser) {})
+
+	ioc.PutNamedFactory("Addr parser (promoter)",
+		func(p AddrParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("Prefix parser (default)",
+		netip.ParsePrefix, func(PrefixParser) {})
+
+	ioc.PutNamedFactory("Prefix parser (promoter)",
+		func(p PrefixParser) (Parser, error) { return p, nil })
+
+	ioc.DefaultPutNamed("File mode parser (default)",
+		parseFileMode, func(FileModeParser) {})
+
+	ioc.PutNamedFactory("File mode parser (promoter)",
+		func(p FileModeParser) (Parser, error) { return p, nil })
+
 }
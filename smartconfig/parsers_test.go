@@ -0,0 +1,123 @@
+package smartconfig_test
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type server_config struct {
+	Url      *url.URL
+	Ip       net.IP
+	Network  *net.IPNet
+	Addr     netip.Addr
+	Prefix   netip.Prefix
+	Mode     os.FileMode
+	Timeout  time.Duration
+	Started  time.Time
+	Buffer   ByteSize
+	Workers  uint8
+	Offset   int16
+	Capacity int64
+	Ratio    float32
+}
+
+var _ = Describe("Parsers", func() {
+
+	It("should parse the standard types", func() {
+
+		TestConfigure("server", &server_config{})
+		config.TestMap(map[string]string{
+			"server.url":      "https://example.com:8443/api",
+			"server.ip":       "192.168.0.1",
+			"server.network":  "10.0.0.0/8",
+			"server.addr":     "::1",
+			"server.prefix":   "fd00::/8",
+			"server.mode":     "0640",
+			"server.timeout":  "1m30s",
+			"server.started":  "2023-06-15T10:30:00Z",
+			"server.buffer":   "10MiB",
+			"server.workers":  "16",
+			"server.offset":   "-300",
+			"server.capacity": "9000000000",
+			"server.ratio":    "0.75",
+		})
+
+		ioc.CallInjected(func(injected *server_config) {
+			Expect(injected.Url.Host).To(Equal("example.com:8443"))
+			Expect(injected.Ip.String()).To(Equal("192.168.0.1"))
+			Expect(injected.Network.Contains(net.ParseIP("10.1.2.3"))).To(BeTrue())
+			Expect(injected.Addr).To(Equal(netip.IPv6Loopback()))
+			Expect(injected.Prefix.Bits()).To(Equal(8))
+			Expect(injected.Mode).To(Equal(os.FileMode(0640)))
+			Expect(injected.Timeout).To(Equal(90 * time.Second))
+			Expect(injected.Started).To(Equal(time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)))
+			Expect(injected.Buffer).To(Equal(10 * MiB))
+			Expect(injected.Workers).To(Equal(uint8(16)))
+			Expect(injected.Offset).To(Equal(int16(-300)))
+			Expect(injected.Capacity).To(Equal(int64(9000000000)))
+			Expect(injected.Ratio).To(Equal(float32(0.75)))
+		})
+
+	})
+
+	It("should reject out of range values", func() {
+
+		TestConfigure("server", &server_config{})
+		config.Test("server.workers", "300")
+
+		Expect(ioc.ErroneousCallInjected(func(*server_config) {})).To(HaveOccurred())
+
+	})
+
+	It("should parse times with the configured layouts", func() {
+
+		parser := NewTimeParser([]string{"RFC1123", "02/01/2006"})
+
+		Expect(parser("15/06/2023")).To(Equal(time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)))
+		Expect(parser("Thu, 15 Jun 2023 10:30:00 UTC")).To(Equal(time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)))
+
+		_, err := parser("2023-06-15")
+		Expect(err).To(MatchError(ContainSubstring("doesn't match any time layout")))
+
+	})
+
+	It("should read the time layouts from the configuration", func() {
+
+		config.TestMap(map[string]string{
+			TIME_LAYOUTS:     "02/01/2006 | RFC1123",
+			"server.started": "15/06/2023",
+		})
+
+		ioc.CallInjected(func(nav NavConfig) {
+			started, _, err := Lookup[time.Time](nav.Get("server.started"))
+			Expect(err).To(Succeed())
+			Expect(started).To(Equal(time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)))
+		})
+
+	})
+
+	It("should read the time layouts containing a comma", func() {
+
+		config.TestMap(map[string]string{
+			TIME_LAYOUTS:     "Jan 2, 2006|RFC3339",
+			"server.started": "Jun 15, 2023",
+		})
+
+		ioc.CallInjected(func(nav NavConfig) {
+			started, _, err := Lookup[time.Time](nav.Get("server.started"))
+			Expect(err).To(Succeed())
+			Expect(started).To(Equal(time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)))
+		})
+
+	})
+
+})
@@ -0,0 +1,169 @@
+package smartconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/b-charles/pigs/json"
+)
+
+// WILDCARD is the part of a query path matching any key.
+var WILDCARD = "*"
+
+// Query returns the defined nodes matching the given path, where each part
+// equal to the wildcard matches any key (e.g. 'db.*.url'). Like for the method
+// Get, a path starting with a '.' is applied from the root. The nodes are
+// returned in the order of the keys.
+func Query(config NavConfig, pattern string) []NavConfig {
+
+	nodes := []NavConfig{config}
+	for i, part := range strings.Split(pattern, ".") {
+
+		if i == 0 && part == "" {
+			nodes = []NavConfig{config.Root()}
+			continue
+		}
+
+		matched := make([]NavConfig, 0)
+		for _, node := range nodes {
+			for _, key := range node.Keys() {
+				if part == WILDCARD || part == key {
+					matched = append(matched, node.Child(key))
+				}
+			}
+		}
+		nodes = matched
+
+	}
+
+	defined := make([]NavConfig, 0, len(nodes))
+	for _, node := range nodes {
+		if !isMissing(node) {
+			defined = append(defined, node)
+		}
+	}
+
+	return defined
+
+}
+
+// Find walks the defined descendants of the node, depth first and in the order
+// of the keys, and returns the ones accepted by the predicate.
+func Find(config NavConfig, predicate func(NavConfig) bool) []NavConfig {
+
+	found := make([]NavConfig, 0)
+
+	var walk func(NavConfig)
+	walk = func(node NavConfig) {
+		for _, key := range node.Keys() {
+			child := node.Child(key)
+			if isMissing(child) {
+				continue
+			}
+			if predicate(child) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(config)
+
+	return found
+
+}
+
+// Lookup parses the value of the node with the parser, the inspector or the
+// unmarshaler of T, found in the configurer of the configuration tree. It
+// returns the parsed value, a boolean indicating if the node is defined and an
+// error if the value can not be parsed.
+func Lookup[T any](config NavConfig) (T, bool, error) {
+
+	var value T
+
+	if isMissing(config) {
+		return value, false, nil
+	}
+
+	smartConfigurer, err := boundConfigurer(config)
+	if err != nil {
+		return value, true, err
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	if configurer, err := smartConfigurer.configurers.Get(target); err != nil {
+		return value, true, err
+	} else if !configurer.scalar {
+		return value, true, fmt.Errorf("The type %v is not parsed from a single value.", target)
+	} else if err := configurer.setter(config, reflect.ValueOf(&value).Elem()); err != nil {
+		return value, true, err
+	}
+
+	return value, true, nil
+
+}
+
+// rawValue returns the value of the node before the resolution of the
+// placeholders, if available.
+func rawValue(config NavConfig) string {
+	if raw, ok := config.(interface{ Raw() string }); ok {
+		return raw.Raw()
+	}
+	return config.Value()
+}
+
+// arrayKeys returns true if the keys are the indexes of an array.
+func arrayKeys(keys []string) bool {
+	for i, key := range keys {
+		if key != strconv.Itoa(i) {
+			return false
+		}
+	}
+	return len(keys) > 0
+}
+
+func toJson(config NavConfig, value func(NavConfig) string) json.JsonNode {
+
+	keys := config.Keys()
+	children := make([]NavConfig, 0, len(keys))
+	for _, key := range keys {
+		children = append(children, config.Child(key))
+	}
+
+	if len(keys) == 0 {
+		if v := value(config); v != "" {
+			return json.JsonString(v)
+		}
+		return json.JSON_NULL
+	}
+
+	if value(config) == "" && arrayKeys(keys) {
+		return json.NewJsonArrayMapped(children, func(child NavConfig) json.JsonNode {
+			return toJson(child, value)
+		})
+	}
+
+	b := json.NewJsonBuilder()
+	if v := value(config); v != "" {
+		b.SetString(".", v)
+	}
+	for k, key := range keys {
+		b.Set(json.EscapePath(key), toJson(children[k], value))
+	}
+
+	return b.Build()
+
+}
+
+// JsonOf returns the Json representation of the node: the nodes whose keys are
+// the indexes 0, 1, 2... are represented by arrays.
+func JsonOf(config NavConfig) json.JsonNode {
+	return toJson(config, NavConfig.Value)
+}
+
+// RawJsonOf returns the Json representation of the node like JsonOf, with the
+// values before the resolution of their placeholders.
+func RawJsonOf(config NavConfig) json.JsonNode {
+	return toJson(config, rawValue)
+}
@@ -0,0 +1,558 @@
+package smartconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/b-charles/pigs/json"
+)
+
+var JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+
+/*
+ * Registered roots
+ */
+
+type configuredRoot struct {
+	root   string
+	target reflect.Type
+	opts   []Option
+}
+
+var (
+	configuredRootsMu sync.Mutex
+	configuredRoots   = []configuredRoot{}
+)
+
+// recordRoot records a configured root for the generation of the Json Schema
+// of the whole configuration.
+func recordRoot(root string, target reflect.Type, opts []Option) {
+	configuredRootsMu.Lock()
+	defer configuredRootsMu.Unlock()
+	configuredRoots = append(configuredRoots, configuredRoot{root, target, opts})
+}
+
+/*
+ * Schema tree
+ */
+
+// jsonSchema is a Json Schema under construction: the properties of an object
+// are kept apart to be merged.
+type jsonSchema struct {
+	keywords   map[string]json.JsonNode
+	properties map[string]*jsonSchema
+	required   []string
+}
+
+func newJsonSchema(typ string) *jsonSchema {
+	schema := &jsonSchema{keywords: map[string]json.JsonNode{}}
+	if typ != "" {
+		schema.keywords["type"] = json.JsonString(typ)
+	}
+	return schema
+}
+
+func (self *jsonSchema) typ() string {
+	if typ, ok := self.keywords["type"]; ok {
+		return typ.AsString()
+	}
+	return ""
+}
+
+// property returns the schema of the property at the given dotted path,
+// created as an object if it doesn't exist.
+func (self *jsonSchema) property(path string) *jsonSchema {
+
+	schema := self
+	for _, key := range strings.Split(path, ".") {
+		if schema.properties == nil {
+			schema.properties = map[string]*jsonSchema{}
+		}
+		child, ok := schema.properties[key]
+		if !ok {
+			child = newJsonSchema("object")
+			schema.properties[key] = child
+		}
+		schema = child
+	}
+
+	return schema
+
+}
+
+// setProperty sets the schema of the property at the given dotted path,
+// merged with the already defined schema.
+func (self *jsonSchema) setProperty(path string, schema *jsonSchema) {
+
+	parent, key := self, path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, key = self.property(path[:i]), path[i+1:]
+	}
+
+	if parent.properties == nil {
+		parent.properties = map[string]*jsonSchema{}
+	}
+	if old, ok := parent.properties[key]; ok {
+		old.merge(schema)
+	} else {
+		parent.properties[key] = schema
+	}
+
+}
+
+func (self *jsonSchema) require(path string) {
+
+	parent, key := self, path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, key = self.property(path[:i]), path[i+1:]
+	}
+
+	for _, r := range parent.required {
+		if r == key {
+			return
+		}
+	}
+	parent.required = append(parent.required, key)
+
+}
+
+func (self *jsonSchema) merge(other *jsonSchema) {
+	for k, v := range other.keywords {
+		self.keywords[k] = v
+	}
+	for k, v := range other.properties {
+		self.setProperty(k, v)
+	}
+	for _, r := range other.required {
+		self.require(r)
+	}
+}
+
+// closeProperties sets 'additionalProperties' to false on the object and on
+// its nested objects without explicit additional properties.
+func (self *jsonSchema) closeProperties() {
+	if self.typ() == "object" {
+		if _, ok := self.keywords["additionalProperties"]; !ok {
+			self.keywords["additionalProperties"] = json.JSON_FALSE
+		}
+	}
+	for _, property := range self.properties {
+		property.closeProperties()
+	}
+}
+
+func (self *jsonSchema) Json() json.JsonNode {
+
+	members := make(map[string]json.JsonNode, len(self.keywords)+2)
+	for k, v := range self.keywords {
+		members[k] = v
+	}
+
+	if len(self.properties) > 0 {
+		members["properties"] = json.NewJsonObjectMapped(self.properties,
+			func(property *jsonSchema) json.JsonNode { return property.Json() })
+	}
+
+	if len(self.required) > 0 {
+		required := append([]string{}, self.required...)
+		sort.Strings(required)
+		members["required"] = json.NewJsonArrayStrings(required)
+	}
+
+	switch self.typ() {
+	case "integer", "number", "boolean":
+		return resolvedSchema(members)
+	}
+
+	return json.NewJsonObject(members)
+
+}
+
+// PLACEHOLDER_PATTERN matches the values resolved by the configuration: the
+// values with a placeholder and the encrypted values.
+var PLACEHOLDER_PATTERN = `\$\{.*\}|^ENC\(.*\)$`
+
+// resolvedSchema returns the schema of a number or a boolean, which can also be
+// given as a string resolved by the configuration (see PLACEHOLDER_PATTERN).
+func resolvedSchema(members map[string]json.JsonNode) json.JsonNode {
+
+	typed := make(map[string]json.JsonNode, len(members))
+	schema := map[string]json.JsonNode{}
+	for k, v := range members {
+		if k == "default" {
+			schema[k] = v
+		} else {
+			typed[k] = v
+		}
+	}
+
+	schema["anyOf"] = json.NewJsonArray([]json.JsonNode{
+		json.NewJsonObject(typed),
+		json.NewJsonObject(map[string]json.JsonNode{
+			"type":    json.JsonString("string"),
+			"pattern": json.JsonString(PLACEHOLDER_PATTERN),
+		}),
+	})
+
+	return json.NewJsonObject(schema)
+
+}
+
+// schemaType returns the type of a rendered schema, or the type of its first
+// alternative for the resolved schemas.
+func schemaType(schema json.JsonNode) string {
+	if alternatives := schema.GetMember("anyOf"); alternatives.IsArray() && alternatives.GetLen() > 0 {
+		schema = alternatives.GetElement(0)
+	}
+	if typ := schema.GetMember("type"); typ.IsString() {
+		return typ.AsString()
+	}
+	return ""
+}
+
+/*
+ * Leaf schemas
+ */
+
+// stringSyntax returns true if the type is a number written with a unit, like
+// the durations and the byte sizes.
+func stringSyntax(target reflect.Type) bool {
+	for target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	return target == duration_type || target == bytesize_type
+}
+
+// scalarSchema returns the schema of a type configured by a parser: a boolean
+// or a number for the types of these kinds (named or not), a string for the
+// others and for the numbers written with a unit (durations, sizes, urls...).
+func scalarSchema(target reflect.Type) *jsonSchema {
+
+	if stringSyntax(target) {
+		return newJsonSchema("string")
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		return newJsonSchema("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return newJsonSchema("integer")
+	case reflect.Float32, reflect.Float64:
+		return newJsonSchema("number")
+	default:
+		return newJsonSchema("string")
+	}
+
+}
+
+// typedValue converts a tag value to a Json value of the schema type.
+func typedValue(typ, value string) json.JsonNode {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return json.JsonBool(b)
+		}
+	case "integer":
+		if i, err := strconv.Atoi(value); err == nil {
+			return json.JsonInt(i)
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.JsonFloat(f)
+		}
+	}
+	return json.JsonString(value)
+}
+
+// applyTags adds the keywords defined by the tags of a field.
+func applyTags(schema *jsonSchema, field reflect.StructField) {
+
+	typ := schema.typ()
+	// the bounds of the durations and the byte sizes can't be expressed on
+	// their string syntax
+	unit := typ == "string" && stringSyntax(field.Type)
+
+	if value, ok := field.Tag.Lookup("default"); ok && typ == "array" {
+		itemType := ""
+		if items, ok := schema.keywords["items"]; ok {
+			itemType = schemaType(items)
+		}
+		schema.keywords["default"] = json.NewJsonArrayMapped(splitItems(value),
+			func(item string) json.JsonNode { return typedValue(itemType, item) })
+	} else if ok {
+		schema.keywords["default"] = typedValue(typ, value)
+	}
+
+	bound := func(tag, number, length, items, properties string) {
+		value, ok := field.Tag.Lookup(tag)
+		if !ok || unit {
+			return
+		}
+		keyword := map[string]string{"integer": number, "number": number,
+			"string": length, "array": items, "object": properties}[typ]
+		if keyword == "" {
+			return
+		}
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			if typ == "number" {
+				schema.keywords[keyword] = json.JsonFloat(n)
+			} else {
+				schema.keywords[keyword] = json.JsonInt(int(n))
+			}
+		}
+	}
+
+	bound("min", "minimum", "minLength", "minItems", "minProperties")
+	bound("max", "maximum", "maxLength", "maxItems", "maxProperties")
+	bound("len", "", "minLength", "minItems", "minProperties")
+	bound("len", "", "maxLength", "maxItems", "maxProperties")
+
+	if nonempty, _ := strconv.ParseBool(field.Tag.Get("nonempty")); nonempty {
+		keyword := map[string]string{"string": "minLength", "array": "minItems", "object": "minProperties"}[typ]
+		if _, defined := schema.keywords[keyword]; keyword != "" && !defined && !unit {
+			schema.keywords[keyword] = json.JsonInt(1)
+		}
+	}
+
+	if values, ok := field.Tag.Lookup("oneof"); ok {
+		schema.keywords["enum"] = json.NewJsonArrayMapped(strings.Fields(values),
+			func(v string) json.JsonNode { return typedValue(typ, v) })
+	}
+
+	if pattern, ok := field.Tag.Lookup("regexp"); ok && typ == "string" {
+		schema.keywords["pattern"] = json.JsonString(pattern)
+	}
+
+}
+
+/*
+ * Generation
+ */
+
+// typeSchema returns the schema of the configuration tree of a type. The
+// struct fields with an absolute key are added to the document.
+func (self *SmartConfigurer) typeSchema(target reflect.Type, doc *jsonSchema, visiting map[reflect.Type]bool) (*jsonSchema, error) {
+
+	configurer, err := self.configurers.Get(target)
+	if err != nil {
+		return nil, err
+	} else if configurer.scalar {
+		return configurer.schema(), nil
+	}
+
+	switch target.Kind() {
+
+	case reflect.Pointer:
+		return self.typeSchema(target.Elem(), doc, visiting)
+
+	case reflect.Slice:
+		items, err := self.typeSchema(target.Elem(), doc, visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := newJsonSchema("array")
+		schema.keywords["items"] = items.Json()
+		return schema, nil
+
+	case reflect.Array:
+		items, err := self.typeSchema(target.Elem(), doc, visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := newJsonSchema("array")
+		schema.keywords["items"] = items.Json()
+		schema.keywords["maxItems"] = json.JsonInt(target.Len())
+		return schema, nil
+
+	case reflect.Map:
+		values, err := self.typeSchema(target.Elem(), doc, visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := newJsonSchema("object")
+		schema.keywords["additionalProperties"] = values.Json()
+		if keys := scalarSchema(target.Key()); keys.typ() == "integer" {
+			schema.keywords["propertyNames"] = json.NewJsonObject(map[string]json.JsonNode{
+				"pattern": json.JsonString("^-?[0-9]+$"),
+			})
+		}
+		return schema, nil
+
+	case reflect.Interface:
+		return self.interfaceSchema(target, doc, visiting)
+
+	case reflect.Struct:
+		return self.structSchema(target, doc, visiting)
+
+	default:
+		return nil, fmt.Errorf("No configurer found for type %v.", target)
+
+	}
+
+}
+
+func (self *SmartConfigurer) structSchema(target reflect.Type, doc *jsonSchema, visiting map[reflect.Type]bool) (*jsonSchema, error) {
+
+	schema := newJsonSchema("object")
+	if visiting[target] {
+		return schema, nil
+	}
+	visiting[target] = true
+	defer delete(visiting, target)
+
+	strictStruct := false
+	for f := 0; f < target.NumField(); f++ {
+
+		field := target.Field(f)
+		key, options := fieldKey(field)
+
+		if field.Name == "_" {
+			strictStruct = strictStruct || hasOption(options, "strict")
+			continue
+		}
+
+		if embedded, err := squashedConfigurer(field, self.configurers.Get); err != nil {
+			return nil, fmt.Errorf("Can not squash field '%s' of %v: %w", field.Name, target, err)
+		} else if embedded != nil {
+			promoted, err := self.structSchema(embedded.target, doc, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("Can not describe field '%s' of %v: %w", field.Name, target, err)
+			}
+			for k, v := range promoted.properties {
+				schema.setProperty(k, v)
+			}
+			for _, r := range promoted.required {
+				schema.require(r)
+			}
+			continue
+		}
+
+		sub, err := self.typeSchema(field.Type, doc, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("Can not describe field '%s' of %v: %w", field.Name, target, err)
+		}
+		applyTags(sub, field)
+
+		parent := schema
+		if strings.HasPrefix(key, ".") {
+			parent, key = doc, key[1:]
+		}
+
+		parent.setProperty(key, sub)
+
+		_, hasDefault := field.Tag.Lookup("default")
+		if required, _ := strconv.ParseBool(field.Tag.Get("required")); required && !hasDefault {
+			parent.require(key)
+		}
+
+	}
+
+	if strictStruct {
+		schema.closeProperties()
+	}
+
+	return schema, nil
+
+}
+
+// interfaceSchema describes each variant of an interface, with the name of the
+// variant as discriminator.
+func (self *SmartConfigurer) interfaceSchema(target reflect.Type, doc *jsonSchema, visiting map[reflect.Type]bool) (*jsonSchema, error) {
+
+	variants := self.variants[target]
+	names := variantNames(variants)
+
+	oneOf := make([]json.JsonNode, 0, len(names))
+	for _, name := range names {
+
+		variant, err := self.typeSchema(variants[name].Concrete, doc, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("Can not describe the variant '%v': %w", variants[name], err)
+		}
+
+		discriminator := newJsonSchema("string")
+		discriminator.keywords["const"] = json.JsonString(name)
+		variant.setProperty(DISCRIMINATOR, discriminator)
+		variant.require(DISCRIMINATOR)
+
+		oneOf = append(oneOf, variant.Json())
+
+	}
+
+	schema := newJsonSchema("object")
+	schema.keywords["oneOf"] = json.NewJsonArray(oneOf)
+
+	return schema, nil
+
+}
+
+// rootSchema adds the schema of a configured root to the document.
+func (self *SmartConfigurer) rootSchema(doc *jsonSchema, root string, target reflect.Type, opts []Option) error {
+
+	schema, err := self.typeSchema(target, doc, map[reflect.Type]bool{})
+	if err != nil {
+		return fmt.Errorf("Can not describe the configuration '%s' (%v): %w", root, target, err)
+	}
+
+	if newOptions(opts).strict {
+		schema.closeProperties()
+	}
+
+	if root == "" {
+		doc.merge(schema)
+	} else {
+		doc.setProperty(root, schema)
+	}
+
+	return nil
+
+}
+
+func newDocument() *jsonSchema {
+	doc := newJsonSchema("object")
+	doc.keywords["$schema"] = json.JsonString(JSON_SCHEMA_DIALECT)
+	return doc
+}
+
+// JsonSchemaOf returns the Json Schema of the configuration tree of a
+// configurable, as configured by Configure.
+func (self *SmartConfigurer) JsonSchemaOf(configurable any, opts ...Option) (json.JsonNode, error) {
+
+	target := reflect.TypeOf(configurable)
+	if target != nil && target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+
+	doc := newDocument()
+	if err := self.rootSchema(doc, "", target, opts); err != nil {
+		return nil, err
+	}
+
+	return doc.Json(), nil
+
+}
+
+// JsonSchema returns the Json Schema of the whole configuration tree, with all
+// the roots registered by the functions Configure, DefaultConfigure, Watch...
+// (the roots registered in the test scope are not included).
+func (self *SmartConfigurer) JsonSchema() (json.JsonNode, error) {
+
+	configuredRootsMu.Lock()
+	roots := append([]configuredRoot{}, configuredRoots...)
+	configuredRootsMu.Unlock()
+
+	doc := newDocument()
+	for _, root := range roots {
+		if err := self.rootSchema(doc, root.root, root.target, root.opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc.Json(), nil
+
+}
@@ -0,0 +1,145 @@
+package smartconfig_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	"github.com/b-charles/pigs/json"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type schema_tls_config struct {
+	_    struct{} `config:",strict"`
+	Cert string   `required:"true"`
+}
+
+type schema_server_config struct {
+	Host     string            `required:"true" regexp:"^[a-z.]+$"`
+	Port     int               `default:"8080" min:"1" max:"65535"`
+	Mode     string            `oneof:"http https"`
+	Debug    bool              `config:"log.debug"`
+	Timeout  time.Duration     `default:"30s"`
+	Ratio    float64           `max:"1"`
+	Aliases  []string          `nonempty:"true"`
+	Labels   map[string]string `config:"meta.labels"`
+	Tls      *schema_tls_config
+	Location string `config:".location"`
+}
+
+type schema_workers int
+
+type schema_pool_config struct {
+	Workers schema_workers `default:"4" min:"1" max:"64"`
+	Idle    time.Duration  `default:"1m" min:"1s"`
+	Buffer  ByteSize       `max:"1MB" nonempty:"true"`
+}
+
+type schema_registered_config struct {
+	Name string `required:"true"`
+}
+
+func init() {
+	Configure("schema.registered", &schema_registered_config{})
+	ioc.PutNamed("Schema workers parser", func(value string) (schema_workers, error) {
+		n, err := strconv.Atoi(value)
+		return schema_workers(n), err
+	}, func(Parser) {})
+}
+
+func parseJson(content string) json.JsonNode {
+	node, err := json.ParseString(content)
+	Expect(err).To(Succeed())
+	return node
+}
+
+var _ = Describe("Json Schema", func() {
+
+	It("should describe a configured struct", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchemaOf(&schema_server_config{})
+			Expect(err).To(Succeed())
+
+			Expect(schema.String()).To(Equal(parseJson(`{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"properties": {
+					"aliases": {"items": {"type": "string"}, "minItems": 1, "type": "array"},
+					"host": {"pattern": "^[a-z.]+$", "type": "string"},
+					"location": {"type": "string"},
+					"log": {"properties": {"debug": {"anyOf": [{"type": "boolean"}, {"pattern": "\\$\\{.*\\}|^ENC\\(.*\\)$", "type": "string"}]}}, "type": "object"},
+					"meta": {"properties": {"labels": {"additionalProperties": {"type": "string"}, "type": "object"}}, "type": "object"},
+					"mode": {"enum": ["http", "https"], "type": "string"},
+					"port": {"anyOf": [{"maximum": 65535, "minimum": 1, "type": "integer"}, {"pattern": "\\$\\{.*\\}|^ENC\\(.*\\)$", "type": "string"}], "default": 8080},
+					"ratio": {"anyOf": [{"maximum": 1, "type": "number"}, {"pattern": "\\$\\{.*\\}|^ENC\\(.*\\)$", "type": "string"}]},
+					"timeout": {"default": "30s", "type": "string"},
+					"tls": {"additionalProperties": false, "properties": {"cert": {"type": "string"}}, "required": ["cert"], "type": "object"}
+				},
+				"required": ["host"],
+				"type": "object"
+			}`).String()))
+
+		})
+
+	})
+
+	It("should describe the named numbers", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchemaOf(&schema_pool_config{})
+			Expect(err).To(Succeed())
+
+			Expect(schema.GetMember("properties").String()).To(Equal(parseJson(`{
+				"buffer": {"type": "string"},
+				"idle": {"default": "1m", "type": "string"},
+				"workers": {"anyOf": [{"maximum": 64, "minimum": 1, "type": "integer"}, {"pattern": "\\$\\{.*\\}|^ENC\\(.*\\)$", "type": "string"}], "default": 4}
+			}`).String()))
+
+		})
+
+	})
+
+	It("should describe the registered roots", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchema()
+			Expect(err).To(Succeed())
+
+			Expect(schema.GetMember("properties").GetMember("schema").String()).To(Equal(parseJson(`{
+				"properties": {
+					"registered": {"properties": {"name": {"type": "string"}}, "required": ["name"], "type": "object"}
+				},
+				"type": "object"
+			}`).String()))
+
+		})
+
+	})
+
+	It("should describe a strict root", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchemaOf(&schema_registered_config{}, Strict())
+			Expect(err).To(Succeed())
+			Expect(schema.GetMember("additionalProperties").String()).To(Equal("false"))
+
+		})
+
+	})
+
+})
@@ -3,6 +3,7 @@ package smartconfig_test
 import (
 	"testing"
 
+	"github.com/b-charles/pigs/ioc"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -11,3 +12,10 @@ func TestConfig(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Smart Config Suite")
 }
+
+// The test components are only released by a successful call: the ones of the
+// specs expecting an injection failure are released after each spec.
+var _ = AfterEach(func() {
+	ioc.TestPut("release the test components")
+	ioc.CallInjected(func() {})
+})
@@ -9,17 +9,28 @@ import (
 	"github.com/b-charles/pigs/memfun"
 )
 
+// configurer configures the values of a type. A scalar configurer (parser,
+// inspector or unmarshaler) configures a value from a node as a whole, the
+// other ones from the children of the node.
 type configurer struct {
 	target reflect.Type
+	scalar bool
 	setter func(NavConfig, reflect.Value) error
+	schema func() *jsonSchema
+	keys   func() ([]string, error)
 }
 
 type SmartConfigurer struct {
 	config      NavConfig
 	configurers memfun.MemFun[reflect.Type, *configurer]
+	variants    map[reflect.Type]map[string]*Variant
 }
 
-func findConfigurer(target reflect.Type, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+func findConfigurer(target reflect.Type, variants map[reflect.Type]map[string]*Variant, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+
+	if configurer := newUnmarshalerConfigurer(target); configurer != nil {
+		return configurer, nil
+	}
 
 	if target.Kind() == reflect.Pointer {
 		return newPointerConfigurer(target, recfun)
@@ -33,17 +44,32 @@ func findConfigurer(target reflect.Type, recfun func(reflect.Type) (*configurer,
 		return newSliceConfigurer(target, recfun)
 	}
 
-	if target.Kind() == reflect.Map && target.Key() == string_type {
+	if target.Kind() == reflect.Array {
+		return newArrayConfigurer(target, recfun)
+	}
+
+	if target.Kind() == reflect.Map {
 		return newMapConfigurer(target, recfun)
 	}
 
+	if target.Kind() == reflect.Interface {
+		return newInterfaceConfigurer(target, variants[target], recfun)
+	}
+
 	return nil, fmt.Errorf("No configurer found for type %v.", target)
 
 }
 
-func newSmartConfigurer(config NavConfig, parsers []Parser, inspectors []Inspector) (*SmartConfigurer, error) {
+func newSmartConfigurer(config NavConfig, parsers []Parser, inspectors []Inspector, variants []*Variant) (*SmartConfigurer, error) {
+
+	indexedVariants, err := indexVariants(variants)
+	if err != nil {
+		return nil, err
+	}
 
-	configurers := memfun.NewMemFun(findConfigurer)
+	configurers := memfun.NewMemFun(func(target reflect.Type, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+		return findConfigurer(target, indexedVariants, recfun)
+	})
 
 	for _, parser := range parsers {
 
@@ -86,11 +112,21 @@ func newSmartConfigurer(config NavConfig, parsers []Parser, inspectors []Inspect
 	return &SmartConfigurer{
 		config:      config,
 		configurers: configurers,
+		variants:    indexedVariants,
 	}, nil
 
 }
 
-func (self *SmartConfigurer) Configure(root string, configurable any) error {
+// injectedSmartConfigurer returns the configurer bound to the injected tree,
+// or a new one if the tree is not bound (a tree overridden in the tests...).
+func injectedSmartConfigurer(config NavConfig, parsers []Parser, inspectors []Inspector, variants []*Variant) (*SmartConfigurer, error) {
+	if configurer, err := boundConfigurer(config); err == nil {
+		return configurer, nil
+	}
+	return newSmartConfigurer(config, parsers, inspectors, variants)
+}
+
+func (self *SmartConfigurer) Configure(root string, configurable any, opts ...Option) error {
 
 	value := reflect.ValueOf(configurable)
 	if value.Kind() == reflect.Pointer {
@@ -100,9 +136,20 @@ func (self *SmartConfigurer) Configure(root string, configurable any) error {
 		return fmt.Errorf("The value '%v' is not settable.", configurable)
 	}
 
+	return self.configure(self.config.Get(root), value, opts)
+
+}
+
+// configure sets the value from the given node of the configuration tree.
+func (self *SmartConfigurer) configure(config NavConfig, value reflect.Value, opts []Option) error {
+
+	if newOptions(opts).strict {
+		config = strict(config)
+	}
+
 	if configurer, err := self.configurers.Get(value.Type()); err != nil {
 		return err
-	} else if err := configurer.setter(self.config.Get(root), value); err != nil {
+	} else if err := configurer.setter(config, value); err != nil {
 		return err
 	}
 
@@ -120,10 +167,10 @@ func (self *SmartConfigurer) String() string {
 }
 
 func init() {
-	ioc.PutNamedFactory("Smart Configuration", newSmartConfigurer)
+	ioc.PutNamedFactory("Smart Configuration", injectedSmartConfigurer)
 }
 
-func createConfig(root string, configurable any) any {
+func createConfig(root string, configurable any, opts []Option) any {
 
 	factoryType := reflect.FuncOf(
 		[]reflect.Type{smartConfigurer_type},
@@ -133,7 +180,7 @@ func createConfig(root string, configurable any) any {
 	factory := reflect.MakeFunc(factoryType, func(args []reflect.Value) []reflect.Value {
 
 		smartConfigurer := args[0].Interface().(*SmartConfigurer)
-		if err := smartConfigurer.Configure(root, configurable); err != nil {
+		if err := smartConfigurer.Configure(root, configurable, opts...); err != nil {
 			return []reflect.Value{reflect.ValueOf(configurable), reflect.ValueOf(err)}
 		} else {
 			return []reflect.Value{reflect.ValueOf(configurable), reflect.Zero(error_type)}
@@ -145,26 +192,30 @@ func createConfig(root string, configurable any) any {
 
 }
 
-func DefaultConfigure(root string, configurable any) {
-	ioc.DefaultPutFactory(createConfig(root, configurable))
+func DefaultConfigure(root string, configurable any, opts ...Option) {
+	recordRoot(root, reflect.TypeOf(configurable), opts)
+	ioc.DefaultPutFactory(createConfig(root, configurable, opts))
 }
 
-func DefaultConfigureNamed(name string, root string, configurable any) {
-	ioc.DefaultPutNamedFactory(name, createConfig(root, configurable))
+func DefaultConfigureNamed(name string, root string, configurable any, opts ...Option) {
+	recordRoot(root, reflect.TypeOf(configurable), opts)
+	ioc.DefaultPutNamedFactory(name, createConfig(root, configurable, opts))
 }
 
-func Configure(root string, configurable any) {
-	ioc.PutFactory(createConfig(root, configurable))
+func Configure(root string, configurable any, opts ...Option) {
+	recordRoot(root, reflect.TypeOf(configurable), opts)
+	ioc.PutFactory(createConfig(root, configurable, opts))
 }
 
-func ConfigureNamed(name string, root string, configurable any) {
-	ioc.PutNamedFactory(name, createConfig(root, configurable))
+func ConfigureNamed(name string, root string, configurable any, opts ...Option) {
+	recordRoot(root, reflect.TypeOf(configurable), opts)
+	ioc.PutNamedFactory(name, createConfig(root, configurable, opts))
 }
 
-func TestConfigure(root string, configurable any) {
-	ioc.TestPutFactory(createConfig(root, configurable))
+func TestConfigure(root string, configurable any, opts ...Option) {
+	ioc.TestPutFactory(createConfig(root, configurable, opts))
 }
 
-func TestConfigureNamed(name string, root string, configurable any) {
-	ioc.TestPutFactory(name, createConfig(root, configurable))
+func TestConfigureNamed(name string, root string, configurable any, opts ...Option) {
+	ioc.TestPutNamedFactory(name, createConfig(root, configurable, opts))
 }
@@ -1,6 +1,9 @@
 package smartconfig_test
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/b-charles/pigs/config"
 	"github.com/b-charles/pigs/ioc"
 	. "github.com/b-charles/pigs/smartconfig"
@@ -37,6 +40,26 @@ type complex_nested_config struct {
 	Map  map[string]bool
 }
 
+type priority int
+
+func (self *priority) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*self = 1
+	case "high":
+		*self = 2
+	default:
+		return fmt.Errorf("Unknown priority '%s'.", text)
+	}
+	return nil
+}
+
+type keyed_config struct {
+	Coords  [3]float64
+	Ports   map[int]string
+	Budgets map[priority]int
+}
+
 var _ = Describe("Smart configuration", func() {
 
 	It("should accept empty config", func() {
@@ -113,4 +136,58 @@ var _ = Describe("Smart configuration", func() {
 
 	})
 
+	It("should configure arrays and maps with parsed keys", func() {
+
+		TestConfigure("keyed", &keyed_config{})
+		config.TestMap(map[string]string{
+			"keyed.coords.0":     "1.5",
+			"keyed.coords.1":     "2.5",
+			"keyed.ports.80":     "http",
+			"keyed.ports.443":    "https",
+			"keyed.budgets.low":  "10",
+			"keyed.budgets.high": "100",
+		})
+
+		ioc.CallInjected(func(injected *keyed_config) {
+			Expect(injected).To(Equal(&keyed_config{
+				Coords:  [3]float64{1.5, 2.5, 0},
+				Ports:   map[int]string{80: "http", 443: "https"},
+				Budgets: map[priority]int{1: 10, 2: 100},
+			}))
+		})
+
+	})
+
+	It("should report the invalid keys and the too long arrays", func() {
+
+		TestConfigure("keyed", &keyed_config{})
+		config.TestMap(map[string]string{
+			"keyed.coords.0":       "1",
+			"keyed.coords.1":       "2",
+			"keyed.coords.2":       "3",
+			"keyed.coords.3":       "4",
+			"keyed.ports.http":     "80",
+			"keyed.budgets.urgent": "1000",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*keyed_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Paths()).To(Equal([]string{"keyed.coords", "keyed.ports.http", "keyed.budgets.urgent"}))
+
+	})
+
+	It("should reject the map keys not parsed from a single value", func() {
+
+		TestConfigure("keyed", &map[struct{ A string }]string{})
+		config.TestMap(map[string]string{
+			"keyed.a": "b",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*map[struct{ A string }]string) {})
+		Expect(err).To(MatchError(ContainSubstring("The keys of a map should be parsed from a single value")))
+
+	})
+
 })
@@ -3,6 +3,7 @@ package smartconfig
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -58,6 +59,81 @@ func newPointerConfigurer(target reflect.Type, recfun func(reflect.Type) (*confi
 
 // Struct configurer
 
+// fieldKey returns the configuration key of a struct field, and the options of
+// its tag 'config'.
+func fieldKey(field reflect.StructField) (string, string) {
+	key, options, _ := strings.Cut(field.Tag.Get("config"), ",")
+	if key == "" {
+		key = strings.ToLower(field.Name)
+	}
+	return key, options
+}
+
+// squashedNavConfig is the node of a parent struct, given to the struct whose
+// fields are promoted: the unknown keys are checked by the parent.
+type squashedNavConfig struct {
+	NavConfig
+}
+
+// squashedConfigurer returns the configurer of the struct whose fields are
+// promoted in the parent struct: an embedded struct without explicit key, or a
+// struct field with the option 'squash'. It returns nil for the other fields.
+func squashedConfigurer(field reflect.StructField, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+
+	name, options, _ := strings.Cut(field.Tag.Get("config"), ",")
+	explicit := hasOption(options, "squash")
+	if !explicit && (!field.Anonymous || name != "") {
+		return nil, nil
+	}
+
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		if explicit {
+			return nil, fmt.Errorf("Only structs can be squashed, not %v.", field.Type)
+		}
+		return nil, nil
+	}
+
+	configurer, err := recfun(typ)
+	if err != nil {
+		return nil, err
+	} else if configurer.keys == nil {
+		if explicit {
+			return nil, fmt.Errorf("The type %v is configured as a whole, and can not be squashed.", typ)
+		}
+		return nil, nil
+	}
+
+	if field.Type.Kind() == reflect.Pointer && !field.IsExported() {
+		return nil, fmt.Errorf("The embedded pointer %v is not exported.", field.Type)
+	}
+
+	return configurer, nil
+
+}
+
+// defaultItems returns true if the default value of a field of the given type
+// is a list of items, split like the list keys given as one value. A default
+// value is rejected for the maps.
+func defaultItems(typ reflect.Type, recfun func(reflect.Type) (*configurer, error)) (bool, error) {
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if configurer, err := recfun(typ); err != nil || configurer.scalar {
+		return false, err
+	} else if typ.Kind() == reflect.Map {
+		return false, fmt.Errorf("A default value can't be defined for the map %v.", typ)
+	} else {
+		return typ.Kind() == reflect.Slice, nil
+	}
+
+}
+
 func newStructConfigurer(target reflect.Type, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
 
 	if target.Kind() != reflect.Struct {
@@ -65,61 +141,142 @@ func newStructConfigurer(target reflect.Type, recfun func(reflect.Type) (*config
 	}
 
 	var (
-		once     sync.Once
-		subError error
+		once         sync.Once
+		subError     error
+		strictStruct bool
+		known        []string
+		validated    = reflect.PointerTo(target).Implements(validator_type)
 	)
 
 	nfields := target.NumField()
-	configurers := make([]func(config NavConfig, receiver reflect.Value) error, nfields, nfields)
+	configurers := make([]func(config NavConfig, receiver reflect.Value, errs *ConfigurationErrors), nfields, nfields)
 
-	return &configurer{
-		target: target,
-		setter: func(config NavConfig, receiver reflect.Value) error {
+	setup := func() {
 
-			once.Do(func() {
+		for fieldNum := 0; fieldNum < nfields; fieldNum++ {
+			f := fieldNum
+
+			field := target.Field(f)
 
-				for fieldNum := 0; fieldNum < nfields; fieldNum++ {
-					f := fieldNum
+			key, tagOptions := fieldKey(field)
+
+			if field.Name == "_" {
+				strictStruct = strictStruct || hasOption(tagOptions, "strict")
+				continue
+			}
 
-					field := target.Field(f)
+			embedded, err := squashedConfigurer(field, recfun)
+			if err != nil {
+				subError = fmt.Errorf("Can not squash field '%s' of %v: %w", field.Name, target, err)
+				return
+			} else if embedded != nil {
 
-					if !field.IsExported() {
-						subError = fmt.Errorf("The field '%s' of %v is not exported.", field.Name, target)
-						return
+				embeddedKeys, err := embedded.keys()
+				if err != nil {
+					subError = fmt.Errorf("Can not squash field '%s' of %v: %w", field.Name, target, err)
+					return
+				}
+				known = append(known, embeddedKeys...)
+
+				configurers[f] = func(config NavConfig, receiver reflect.Value, errs *ConfigurationErrors) {
+
+					receiverField := receiver.Field(f)
+					if receiverField.Kind() == reflect.Pointer {
+						if receiverField.IsNil() {
+							receiverField.Set(reflect.New(receiverField.Type().Elem()))
+						}
+						receiverField = receiverField.Elem()
 					}
 
-					key := field.Tag.Get("config")
-					if key == "" {
-						key = strings.ToLower(field.Name)
+					if err := embedded.setter(&squashedNavConfig{config}, receiverField); err != nil {
+						errs.add(config.Path(), err)
 					}
 
-					if configurer, e := recfun(field.Type); e != nil {
+				}
 
-						subError = fmt.Errorf("Can not configure field '%s' of %v: %w",
-							field.Name, target, e)
-						return
+				continue
 
-					} else {
+			}
+
+			if !field.IsExported() {
+				subError = fmt.Errorf("The field '%s' of %v is not exported.", field.Name, target)
+				return
+			}
+
+			known = append(known, key)
+
+			defaultValue, hasDefault := field.Tag.Lookup("default")
+			required := false
+			if tag, p := field.Tag.Lookup("required"); p {
+				if r, err := strconv.ParseBool(tag); err != nil {
+					subError = fmt.Errorf("Invalid tag 'required' of the field '%s' of %v: %w",
+						field.Name, target, err)
+					return
+				} else {
+					required = r
+				}
+			}
+
+			rules, err := fieldRules(field)
+			if err != nil {
+				subError = fmt.Errorf("Invalid validation of the field '%s' of %v: %w",
+					field.Name, target, err)
+				return
+			}
 
-						configurers[f] = func(config NavConfig, receiver reflect.Value) error {
+			if configurer, e := recfun(field.Type); e != nil {
 
-							receiverField := receiver.Field(f)
-							sub := config.Get(key)
+				subError = fmt.Errorf("Can not configure field '%s' of %v: %w",
+					field.Name, target, e)
+				return
 
-							if err := configurer.setter(sub, receiverField); err != nil {
-								return fmt.Errorf("Error during configuration of '%s' of %v (path: %s): %w",
-									field.Name, target, sub.Path(), err)
-							} else {
-								return nil
-							}
+			} else if items, err := defaultItems(field.Type, recfun); hasDefault && err != nil {
 
+				subError = fmt.Errorf("Invalid tag 'default' of the field '%s' of %v: %w",
+					field.Name, target, err)
+				return
+
+			} else {
+
+				configurers[f] = func(config NavConfig, receiver reflect.Value, errs *ConfigurationErrors) {
+
+					receiverField := receiver.Field(f)
+					sub := config.Get(key)
+
+					if isMissing(sub) {
+						if hasDefault && items {
+							sub = withItems(sub, defaultValue)
+						} else if hasDefault {
+							sub = withValue(sub, defaultValue)
+						} else if required {
+							errs.add(sub.Path(), ErrMissingKey)
+							return
 						}
+					}
 
+					if err := configurer.setter(sub, receiverField); err != nil {
+						errs.add(sub.Path(), err)
+					} else {
+						validate(rules, sub.Path(), receiverField, errs)
 					}
 
 				}
 
-			})
+			}
+
+		}
+
+	}
+
+	return &configurer{
+		target: target,
+		keys: func() ([]string, error) {
+			once.Do(setup)
+			return known, subError
+		},
+		setter: func(config NavConfig, receiver reflect.Value) error {
+
+			once.Do(setup)
 			if subError != nil {
 				return subError
 			}
@@ -129,13 +286,30 @@ func newStructConfigurer(target reflect.Type, recfun func(reflect.Type) (*config
 				return fmt.Errorf("Unexpected value '%v' (%v): not a %v.", receiver, typ, target)
 			}
 
+			squashed := false
+			if s, ok := config.(*squashedNavConfig); ok {
+				config, squashed = s.NavConfig, true
+			}
+
+			if strictStruct {
+				config = strict(config)
+			}
+
+			errs := &ConfigurationErrors{}
+			if isStrict(config) && !squashed {
+				checkUnknownKeys(config, known, errs)
+			}
 			for _, funconfig := range configurers {
-				if err := funconfig(config, receiver); err != nil {
-					return err
+				if funconfig != nil {
+					funconfig(config, receiver, errs)
 				}
 			}
 
-			return nil
+			if validated && len(errs.Errors) == 0 {
+				callValidator(config.Path(), receiver, errs)
+			}
+
+			return errs.err()
 
 		},
 	}, nil
@@ -181,16 +355,87 @@ func newSliceConfigurer(target reflect.Type, recfun func(reflect.Type) (*configu
 			keys := config.Keys()
 			value := reflect.MakeSlice(target, len(keys), len(keys))
 
+			errs := &ConfigurationErrors{}
 			for k, key := range keys {
 
 				v := value.Index(k)
 				sub := config.Child(key)
 
 				if err := subConfigurer.setter(sub, v); err != nil {
-					return fmt.Errorf("Error during configuring element #%d of %v (config path: '%s'): %w",
-						k, typ, sub.Path(), err)
+					errs.add(sub.Path(), err)
+				}
+
+			}
+
+			if err := errs.err(); err != nil {
+				return err
+			}
+
+			receiver.Set(value)
+
+			return nil
+
+		},
+	}, nil
+
+}
+
+// Array configurer
+
+func newArrayConfigurer(target reflect.Type, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+
+	if target.Kind() != reflect.Array {
+		return nil, fmt.Errorf("The target '%v' is not an array.", target)
+	}
+
+	elementType := target.Elem()
+
+	var (
+		once          sync.Once
+		subConfigurer *configurer
+		subError      error
+	)
+
+	return &configurer{
+		target: target,
+		setter: func(config NavConfig, receiver reflect.Value) error {
+
+			typ := receiver.Type()
+			if typ != target {
+				return fmt.Errorf("Unexpected value '%v' (%v): not a %v.", receiver, typ, target)
+			}
+
+			once.Do(func() {
+				if configurer, err := recfun(elementType); err != nil {
+					subError = fmt.Errorf("Can not configure array of %v: %w", elementType, err)
+				} else {
+					subConfigurer = configurer
 				}
+			})
+			if subError != nil {
+				return subError
+			}
 
+			keys := config.Keys()
+			if len(keys) > target.Len() {
+				return fmt.Errorf("Too many elements for %v: %d.", target, len(keys))
+			}
+
+			value := reflect.New(target).Elem()
+
+			errs := &ConfigurationErrors{}
+			for k, key := range keys {
+
+				sub := config.Child(key)
+
+				if err := subConfigurer.setter(sub, value.Index(k)); err != nil {
+					errs.add(sub.Path(), err)
+				}
+
+			}
+
+			if err := errs.err(); err != nil {
+				return err
 			}
 
 			receiver.Set(value)
@@ -209,14 +454,13 @@ func newMapConfigurer(target reflect.Type, recfun func(reflect.Type) (*configure
 	if target.Kind() != reflect.Map {
 		return nil, fmt.Errorf("The target '%v' is not a map.", target)
 	}
-	if target.Key() != string_type {
-		return nil, fmt.Errorf("The key type of '%v' is not 'string'.", target)
-	}
 
+	keyType := target.Key()
 	elementType := target.Elem()
 
 	var (
 		once          sync.Once
+		keyConfigurer *configurer
 		subConfigurer *configurer
 		subError      error
 	)
@@ -231,6 +475,16 @@ func newMapConfigurer(target reflect.Type, recfun func(reflect.Type) (*configure
 			}
 
 			once.Do(func() {
+				if configurer, err := recfun(keyType); err != nil {
+					subError = fmt.Errorf("Can not configure map keys of %v: %w", keyType, err)
+				} else if !configurer.scalar {
+					subError = fmt.Errorf("The keys of a map should be parsed from a single value, not a %v.", keyType)
+				} else {
+					keyConfigurer = configurer
+				}
+				if subError != nil {
+					return
+				}
 				if configurer, err := recfun(elementType); err != nil {
 					subError = fmt.Errorf("Can not configure map of %v: %w", elementType, err)
 				} else {
@@ -243,18 +497,29 @@ func newMapConfigurer(target reflect.Type, recfun func(reflect.Type) (*configure
 
 			value := reflect.MakeMap(target)
 
+			errs := &ConfigurationErrors{}
 			for _, key := range config.Keys() {
 
 				sub := config.Child(key)
 
+				k := reflect.New(keyType)
+				if err := keyConfigurer.setter(withValue(sub, key), k.Elem()); err != nil {
+					errs.add(sub.Path(), fmt.Errorf("Invalid key '%s': %w", key, err))
+					continue
+				}
+
 				v := reflect.New(elementType)
 				if err := subConfigurer.setter(sub, v.Elem()); err != nil {
-					return fmt.Errorf("Error during configuring element '%s' of %v (config path: '%s'): %w",
-						key, typ, sub.Path(), err)
+					errs.add(sub.Path(), err)
+					continue
 				}
 
-				value.SetMapIndex(reflect.ValueOf(key), v.Elem())
+				value.SetMapIndex(k.Elem(), v.Elem())
+
+			}
 
+			if err := errs.err(); err != nil {
+				return err
 			}
 
 			receiver.Set(value)
@@ -0,0 +1,161 @@
+package smartconfig_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type Timeouts struct {
+	Read  time.Duration `default:"5s"`
+	Write time.Duration `default:"5s"`
+}
+
+type Credentials struct {
+	User     string `required:"true"`
+	Password string
+}
+
+type Stamp struct {
+	time.Time
+}
+
+type squashed_client_config struct {
+	Timeouts
+	*Credentials
+	Pool  pool_settings `config:",squash"`
+	Stamp Stamp         `config:"stamp"`
+	Url   string
+}
+
+type pool_settings struct {
+	Size int `default:"10"`
+}
+
+type squashed_keyed_config struct {
+	Timeouts `config:"timeouts"`
+	Url      string
+}
+
+type squashed_strict_config struct {
+	_ struct{} `config:",strict"`
+	Timeouts
+	Url string
+}
+
+type squashed_invalid_config struct {
+	Url string `config:",squash"`
+}
+
+var _ = Describe("Squash", func() {
+
+	It("should promote the fields of the embedded and squashed structs", func() {
+
+		TestConfigure("client", &squashed_client_config{})
+		config.TestMap(map[string]string{
+			"client.read":  "1s",
+			"client.user":  "admin",
+			"client.size":  "3",
+			"client.stamp": "2023-01-02T03:04:05Z",
+			"client.url":   "http://localhost",
+		})
+
+		ioc.CallInjected(func(injected *squashed_client_config) {
+			Expect(injected.Timeouts).To(Equal(Timeouts{Read: time.Second, Write: 5 * time.Second}))
+			Expect(injected.Credentials).To(Equal(&Credentials{User: "admin"}))
+			Expect(injected.Pool.Size).To(Equal(3))
+			Expect(injected.Stamp.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(injected.Url).To(Equal("http://localhost"))
+		})
+
+	})
+
+	It("should report the missing keys of a squashed struct at the level of the parent", func() {
+
+		TestConfigure("client", &squashed_client_config{})
+		config.TestMap(map[string]string{
+			"client.stamp": "2023-01-02T03:04:05Z",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*squashed_client_config) {})
+
+		var missing *MissingKeysError
+		Expect(errors.As(err, &missing)).To(BeTrue())
+		Expect(missing.Paths).To(ContainElement("client.user"))
+
+	})
+
+	It("should keep an embedded struct with an explicit key", func() {
+
+		TestConfigure("client", &squashed_keyed_config{})
+		config.TestMap(map[string]string{
+			"client.timeouts.read": "1s",
+			"client.url":           "http://localhost",
+		})
+
+		ioc.CallInjected(func(injected *squashed_keyed_config) {
+			Expect(injected.Read).To(Equal(time.Second))
+			Expect(injected.Write).To(Equal(5 * time.Second))
+		})
+
+	})
+
+	It("should accept the promoted keys in a strict struct", func() {
+
+		TestConfigure("client", &squashed_strict_config{})
+		config.TestMap(map[string]string{
+			"client.read":  "1s",
+			"client.url":   "http://localhost",
+			"client.other": "value",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*squashed_strict_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Paths()).To(Equal([]string{"client.other"}))
+
+	})
+
+	It("should reject the squash of a value", func() {
+
+		TestConfigure("client", &squashed_invalid_config{})
+		config.TestMap(map[string]string{
+			"client.url": "http://localhost",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*squashed_invalid_config) {})
+		Expect(err).To(MatchError(ContainSubstring("Only structs can be squashed")))
+
+	})
+
+	It("should describe the promoted fields in the Json Schema", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchemaOf(&squashed_strict_config{})
+			Expect(err).To(Succeed())
+
+			Expect(schema.String()).To(Equal(parseJson(`{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"additionalProperties": false,
+				"properties": {
+					"read": {"default": "5s", "type": "string"},
+					"url": {"type": "string"},
+					"write": {"default": "5s", "type": "string"}
+				},
+				"type": "object"
+			}`).String()))
+
+		})
+
+	})
+
+})
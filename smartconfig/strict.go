@@ -0,0 +1,178 @@
+package smartconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Option changes the way a configurable is configured.
+type Option func(*options)
+
+type options struct {
+	strict bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Strict rejects the keys of the configuration tree which are not mapped to
+// any field of the configured structs.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// UnknownKeyError is the cause of the failure of a key which is not mapped to
+// any field of a strict struct, with the closest known key if any.
+type UnknownKeyError struct {
+	Key        string
+	Suggestion string
+}
+
+func (self *UnknownKeyError) Error() string {
+	if self.Suggestion == "" {
+		return fmt.Sprintf("unknown key '%s'", self.Key)
+	}
+	return fmt.Sprintf("unknown key '%s', did you mean '%s'?", self.Key, self.Suggestion)
+}
+
+// strictNavConfig is a node (and a tree of nodes) whose unknown keys are
+// rejected by the struct configurers.
+type strictNavConfig struct {
+	NavConfig
+}
+
+func strict(config NavConfig) NavConfig {
+	if isStrict(config) {
+		return config
+	}
+	return &strictNavConfig{config}
+}
+
+func isStrict(config NavConfig) bool {
+	_, ok := config.(*strictNavConfig)
+	return ok
+}
+
+func (self *strictNavConfig) Child(key string) NavConfig {
+	return &strictNavConfig{self.NavConfig.Child(key)}
+}
+
+func (self *strictNavConfig) Get(key string) NavConfig {
+	return &strictNavConfig{self.NavConfig.Get(key)}
+}
+
+// hasOption returns true if the comma separated options of a tag contain the
+// given option.
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// distance returns the edit distance between two strings, a transposition of
+// two adjacent characters counting for one edit.
+func distance(a, b string) int {
+
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+
+}
+
+func minInt(first int, others ...int) int {
+	m := first
+	for _, o := range others {
+		if o < m {
+			m = o
+		}
+	}
+	return m
+}
+
+// suggest returns the known key the closest to the unknown key, or an empty
+// string if none is close enough.
+func suggest(key string, known []string) string {
+
+	best, bestDistance := "", 3
+	for _, k := range known {
+		if d := distance(key, k); d < bestDistance && d < len([]rune(k)) {
+			best, bestDistance = k, d
+		}
+	}
+
+	return best
+
+}
+
+// checkUnknownKeys records the keys of the node which are neither one of the
+// known keys, nor a parent of one of them.
+func checkUnknownKeys(config NavConfig, known []string, errs *ConfigurationErrors) {
+
+	var check func(node NavConfig, prefix string)
+	check = func(node NavConfig, prefix string) {
+
+		for _, child := range node.Keys() {
+
+			key := path(prefix, child)
+
+			isKnown, isParent := false, false
+			for _, k := range known {
+				if k == key {
+					isKnown = true
+				} else if strings.HasPrefix(k, key+".") {
+					isParent = true
+				}
+			}
+
+			if isKnown {
+				continue
+			} else if isParent {
+				check(node.Child(child), key)
+				continue
+			}
+
+			unknown := &UnknownKeyError{Key: path(config.Path(), key)}
+			if suggestion := suggest(key, known); suggestion != "" {
+				unknown.Suggestion = path(config.Path(), suggestion)
+			}
+			errs.add(unknown.Key, unknown)
+
+		}
+
+	}
+
+	check(config, "")
+
+}
@@ -0,0 +1,139 @@
+package smartconfig_test
+
+import (
+	"errors"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type server_tls_config struct {
+	Cert string
+	Key  string
+}
+
+type http_server_config struct {
+	Host string
+	Port int
+	User string `config:"auth.user"`
+	Tls  server_tls_config
+}
+
+type strict_http_server_config struct {
+	_    struct{} `config:",strict"`
+	Host string
+	Port int
+}
+
+func isDefined(config NavConfig) bool {
+	return config.Value() != "" || len(config.Keys()) > 0
+}
+
+var _ = Describe("Strict mode", func() {
+
+	It("should ignore the unknown keys by default", func() {
+
+		TestConfigure("server", &http_server_config{})
+		config.TestMap(map[string]string{
+			"server.host":     "localhost",
+			"server.port":     "80",
+			"server.prot":     "8080",
+			"server.tls.cert": "a.pem",
+			"server.tls.key":  "a.key",
+		})
+
+		ioc.CallInjected(func(s *http_server_config) {
+			Expect(s.Port).To(Equal(80))
+		})
+
+	})
+
+	It("should not report the keys only looked up", func() {
+
+		config.TestMap(map[string]string{
+			"server.host": "localhost",
+			"server.port": "80",
+		})
+
+		ioc.CallInjected(func(nav NavConfig, configurer *SmartConfigurer, typed TypedConfiguration) {
+
+			Expect(typed.GetInt("server.timeout", 3)).To(Equal(3))
+			Expect(isDefined(nav.Get("server.tls.cert"))).To(BeFalse())
+
+			server := &strict_http_server_config{}
+			Expect(configurer.Configure("server", server, Strict())).To(Succeed())
+			Expect(server.Port).To(Equal(80))
+
+		})
+
+	})
+
+	It("should reject the unknown keys of a named configuration", func() {
+
+		TestConfigureNamed("Strict server", "server", &strict_http_server_config{}, Strict())
+		config.TestMap(map[string]string{
+			"server.host": "localhost",
+			"server.prot": "8080",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*strict_http_server_config) {})
+		Expect(err).To(MatchError(ContainSubstring("server.prot")))
+
+	})
+
+	It("should reject the unknown keys with the option", func() {
+
+		TestConfigure("server", &http_server_config{}, Strict())
+		config.TestMap(map[string]string{
+			"server.host":      "localhost",
+			"server.prot":      "8080",
+			"server.auth.user": "admin",
+			"server.auth.pass": "secret",
+			"server.tls.cert":  "a.pem",
+			"server.tls.kye":   "a.key",
+			"server.debug":     "true",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*http_server_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+
+		unknowns := map[string]string{}
+		for _, e := range errs.Errors {
+			var unknown *UnknownKeyError
+			if errors.As(e, &unknown) {
+				unknowns[unknown.Key] = unknown.Suggestion
+			}
+		}
+		Expect(unknowns).To(Equal(map[string]string{
+			"server.prot":      "server.port",
+			"server.auth.pass": "",
+			"server.tls.kye":   "server.tls.key",
+			"server.debug":     "",
+		}))
+		Expect(errs.Error()).To(ContainSubstring("unknown key 'server.prot', did you mean 'server.port'?"))
+
+	})
+
+	It("should reject the unknown keys of a strict struct", func() {
+
+		TestConfigure("server", &strict_http_server_config{})
+		config.TestMap(map[string]string{
+			"server.host": "localhost",
+			"server.port": "80",
+			"server.hots": "remote",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*strict_http_server_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Paths()).To(Equal([]string{"server.hots"}))
+
+	})
+
+})
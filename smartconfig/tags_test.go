@@ -0,0 +1,95 @@
+package smartconfig_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type pool_config struct {
+	Size    int           `default:"10"`
+	Timeout time.Duration `default:"30s"`
+	Name    string        `required:"true"`
+}
+
+type database_config struct {
+	Url      string `required:"true"`
+	User     string `config:"credentials.user" required:"true"`
+	Password string `config:"credentials.password"`
+	Pool     pool_config
+}
+
+type replicas_config struct {
+	Hosts []string `default:"db1, db2"`
+	Ports []int    `default:"5432,5433"`
+}
+
+type labels_config struct {
+	Labels map[string]string `default:"team=core"`
+}
+
+var _ = Describe("Struct tags", func() {
+
+	It("should use the default values", func() {
+
+		TestConfigure("db", &database_config{})
+		config.TestMap(map[string]string{
+			"db.url":              "postgres://localhost",
+			"db.credentials.user": "admin",
+			"db.pool.name":        "main",
+			"db.pool.timeout":     "5s",
+		})
+
+		ioc.CallInjected(func(db *database_config) {
+			Expect(db).To(Equal(&database_config{
+				Url:  "postgres://localhost",
+				User: "admin",
+				Pool: pool_config{Size: 10, Timeout: 5 * time.Second, Name: "main"},
+			}))
+		})
+
+	})
+
+	It("should report all the missing required keys", func() {
+
+		TestConfigure("db", &database_config{})
+		config.Test("db.credentials.password", "secret")
+
+		err := ioc.ErroneousCallInjected(func(*database_config) {})
+
+		var missing *MissingKeysError
+		Expect(errors.As(err, &missing)).To(BeTrue())
+		Expect(missing.Paths).To(Equal([]string{"db.url", "db.credentials.user", "db.pool.name"}))
+
+	})
+
+	It("should split the default values of the slices", func() {
+
+		TestConfigure("replicas", &replicas_config{})
+		config.Test("replicas.ports.0", "6432")
+
+		ioc.CallInjected(func(replicas *replicas_config) {
+			Expect(replicas).To(Equal(&replicas_config{
+				Hosts: []string{"db1", "db2"},
+				Ports: []int{6432},
+			}))
+		})
+
+	})
+
+	It("should reject the default values of the maps", func() {
+
+		TestConfigure("labels", &labels_config{})
+		config.Test("labels.team", "core")
+
+		err := ioc.ErroneousCallInjected(func(*labels_config) {})
+		Expect(err).To(MatchError(ContainSubstring("Invalid tag 'default' of the field 'Labels'")))
+
+	})
+
+})
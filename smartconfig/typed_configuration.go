@@ -0,0 +1,138 @@
+package smartconfig
+
+import (
+	"strings"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+)
+
+// TypedConfiguration is a Configuration with typed accessors. The Lookup*
+// methods return the parsed value, a boolean indicating if the key is defined
+// and an error if the value can not be parsed. The Get* methods return the
+// given default value if the key is not defined, and panic if the value can not
+// be parsed.
+type TypedConfiguration interface {
+	config.Configuration
+
+	LookupInt(string) (int, bool, error)
+	GetInt(string, int) int
+
+	LookupBool(string) (bool, bool, error)
+	GetBool(string, bool) bool
+
+	LookupDuration(string) (time.Duration, bool, error)
+	GetDuration(string, time.Duration) time.Duration
+
+	LookupList(string) ([]string, bool, error)
+	GetList(string, []string) []string
+
+	LookupSize(string) (ByteSize, bool, error)
+	GetSize(string, ByteSize) ByteSize
+}
+
+type typedConfigurationImpl struct {
+	config.Configuration
+	configurer *SmartConfigurer
+}
+
+func lookupTyped[T any](self *typedConfigurationImpl, key string) (T, bool, error) {
+
+	var value T
+
+	nav := self.configurer.config.Get(key)
+	if !self.HasKey(key) && len(nav.Keys()) == 0 {
+		return value, false, nil
+	}
+
+	if err := self.configurer.Configure(key, &value); err != nil {
+		return value, true, err
+	}
+
+	return value, true, nil
+
+}
+
+func getTyped[T any](self *typedConfigurationImpl, key string, defaultValue T) T {
+	if value, p, err := lookupTyped[T](self, key); err != nil {
+		panic(err)
+	} else if !p {
+		return defaultValue
+	} else {
+		return value
+	}
+}
+
+func (self *typedConfigurationImpl) LookupInt(key string) (int, bool, error) {
+	return lookupTyped[int](self, key)
+}
+
+func (self *typedConfigurationImpl) GetInt(key string, defaultValue int) int {
+	return getTyped(self, key, defaultValue)
+}
+
+func (self *typedConfigurationImpl) LookupBool(key string) (bool, bool, error) {
+	return lookupTyped[bool](self, key)
+}
+
+func (self *typedConfigurationImpl) GetBool(key string, defaultValue bool) bool {
+	return getTyped(self, key, defaultValue)
+}
+
+func (self *typedConfigurationImpl) LookupDuration(key string) (time.Duration, bool, error) {
+	return lookupTyped[time.Duration](self, key)
+}
+
+func (self *typedConfigurationImpl) GetDuration(key string, defaultValue time.Duration) time.Duration {
+	return getTyped(self, key, defaultValue)
+}
+
+func (self *typedConfigurationImpl) LookupList(key string) ([]string, bool, error) {
+
+	if len(self.configurer.config.Get(key).Keys()) > 0 {
+		return lookupTyped[[]string](self, key)
+	}
+
+	value, p, err := self.Lookup(key)
+	if err != nil || !p {
+		return nil, p, err
+	}
+
+	list := []string{}
+	if strings.TrimSpace(value) != "" {
+		for _, item := range strings.Split(value, config.LIST_SEPARATOR) {
+			list = append(list, strings.TrimSpace(item))
+		}
+	}
+
+	return list, true, nil
+
+}
+
+func (self *typedConfigurationImpl) GetList(key string, defaultValue []string) []string {
+	if list, p, err := self.LookupList(key); err != nil {
+		panic(err)
+	} else if !p {
+		return defaultValue
+	} else {
+		return list
+	}
+}
+
+func (self *typedConfigurationImpl) LookupSize(key string) (ByteSize, bool, error) {
+	return lookupTyped[ByteSize](self, key)
+}
+
+func (self *typedConfigurationImpl) GetSize(key string, defaultValue ByteSize) ByteSize {
+	return getTyped(self, key, defaultValue)
+}
+
+func init() {
+
+	ioc.PutNamedFactory("Typed configuration",
+		func(config config.Configuration, configurer *SmartConfigurer) (TypedConfiguration, error) {
+			return &typedConfigurationImpl{config, configurer}, nil
+		})
+
+}
@@ -0,0 +1,69 @@
+package smartconfig_test
+
+import (
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Typed configuration", func() {
+
+	BeforeEach(func() {
+		config.TestMap(map[string]string{
+			"server.port":       "8080",
+			"server.secure":     "true",
+			"server.timeout":    "1m30s",
+			"server.hosts.0":    "alpha",
+			"server.hosts.1":    "beta",
+			"server.aliases":    "gamma, delta",
+			"server.body.limit": "10MiB",
+			"server.broken":     "eleven",
+		})
+	})
+
+	It("should get typed values", func() {
+		ioc.CallInjected(func(config TypedConfiguration) {
+			Expect(config.GetInt("server.port", 80)).To(Equal(8080))
+			Expect(config.GetBool("server.secure", false)).To(BeTrue())
+			Expect(config.GetDuration("server.timeout", time.Second)).To(Equal(90 * time.Second))
+			Expect(config.GetList("server.hosts", nil)).To(Equal([]string{"alpha", "beta"}))
+			Expect(config.GetList("server.aliases", nil)).To(Equal([]string{"gamma", "delta"}))
+			Expect(config.GetSize("server.body.limit", KiB)).To(Equal(10 * MiB))
+		})
+	})
+
+	It("should use default values", func() {
+		ioc.CallInjected(func(config TypedConfiguration) {
+			Expect(config.GetInt("client.port", 80)).To(Equal(80))
+			Expect(config.GetList("client.hosts", []string{"localhost"})).To(Equal([]string{"localhost"}))
+		})
+	})
+
+	It("should return parsing errors", func() {
+		ioc.CallInjected(func(config TypedConfiguration) {
+			_, p, err := config.LookupInt("server.broken")
+			Expect(p).To(BeTrue())
+			Expect(err).To(HaveOccurred())
+			Expect(func() { config.GetInt("server.broken", 0) }).To(Panic())
+		})
+	})
+
+	It("should parse byte sizes", func() {
+		Expect(ParseByteSize("512")).To(Equal(ByteSize(512)))
+		Expect(ParseByteSize("2 kB")).To(Equal(2 * KB))
+		Expect(ParseByteSize("1.5GiB")).To(Equal(3 * GiB / 2))
+		_, err := ParseByteSize("12 parsecs")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseByteSize("9000000PiB")
+		Expect(err).To(MatchError(ContainSubstring("overflows")))
+		_, err = ParseByteSize("99999999999999999999")
+		Expect(err).To(MatchError(ContainSubstring("overflows")))
+		_, err = ParseByteSize("8388608.5PiB")
+		Expect(err).To(MatchError(ContainSubstring("overflows")))
+	})
+
+})
@@ -0,0 +1,71 @@
+package smartconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// ConfigUnmarshaler is implemented by the types which can configure themselves
+// from the configuration tree.
+type ConfigUnmarshaler interface {
+	FromConfig(NavConfig) error
+}
+
+var (
+	configUnmarshaler_type = reflect.TypeOf((*ConfigUnmarshaler)(nil)).Elem()
+	textUnmarshaler_type   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// addressable returns a pointer to the receiver, or to a new value if the
+// receiver is not addressable, with a function to set the receiver.
+func addressable(receiver reflect.Value) (reflect.Value, func()) {
+	if receiver.CanAddr() {
+		return receiver.Addr(), func() {}
+	}
+	ptr := reflect.New(receiver.Type())
+	return ptr, func() { receiver.Set(ptr.Elem()) }
+}
+
+// newUnmarshalerConfigurer returns a configurer for the types implementing
+// ConfigUnmarshaler or encoding.TextUnmarshaler (with a pointer receiver or
+// not), or nil.
+func newUnmarshalerConfigurer(target reflect.Type) *configurer {
+
+	ptrType := reflect.PointerTo(target)
+
+	if ptrType.Implements(configUnmarshaler_type) {
+		return &configurer{
+			target: target,
+			scalar: true,
+			setter: func(config NavConfig, receiver reflect.Value) error {
+				ptr, set := addressable(receiver)
+				if err := ptr.Interface().(ConfigUnmarshaler).FromConfig(config); err != nil {
+					return err
+				}
+				set()
+				return nil
+			},
+			schema: func() *jsonSchema { return newJsonSchema("") },
+		}
+	}
+
+	if ptrType.Implements(textUnmarshaler_type) {
+		return &configurer{
+			target: target,
+			scalar: true,
+			setter: func(config NavConfig, receiver reflect.Value) error {
+				ptr, set := addressable(receiver)
+				if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(config.Value())); err != nil {
+					return fmt.Errorf("Can not unmarshal '%s' to %v: %w", config.Value(), target, err)
+				}
+				set()
+				return nil
+			},
+			schema: func() *jsonSchema { return newJsonSchema("string") },
+		}
+	}
+
+	return nil
+
+}
@@ -0,0 +1,85 @@
+package smartconfig_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type color struct {
+	r, g, b uint8
+}
+
+func (self *color) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "#%02x%02x%02x", &self.r, &self.g, &self.b)
+	return err
+}
+
+type credentials struct {
+	user, password string
+}
+
+func (self *credentials) FromConfig(config NavConfig) error {
+	user, password, found := strings.Cut(config.Value(), ":")
+	if !found {
+		user, password = config.Child("user").Value(), config.Child("password").Value()
+	}
+	if user == "" {
+		return fmt.Errorf("No user defined at '%s'.", config.Path())
+	}
+	self.user, self.password = user, password
+	return nil
+}
+
+type theme_config struct {
+	Background color
+	Palette    []color
+	Accent     *color
+	Admin      credentials
+	Guests     map[string]credentials
+}
+
+var _ = Describe("Unmarshalers", func() {
+
+	It("should use TextUnmarshaler and FromConfig", func() {
+
+		TestConfigure("theme", &theme_config{})
+		config.TestMap(map[string]string{
+			"theme.background":            "#102030",
+			"theme.palette.0":             "#ff0000",
+			"theme.palette.1":             "#00ff00",
+			"theme.accent":                "#0000ff",
+			"theme.admin":                 "root:toor",
+			"theme.guests.alice.user":     "alice",
+			"theme.guests.alice.password": "wonderland",
+		})
+
+		ioc.CallInjected(func(theme *theme_config) {
+			Expect(theme.Background).To(Equal(color{0x10, 0x20, 0x30}))
+			Expect(theme.Palette).To(Equal([]color{{0xff, 0, 0}, {0, 0xff, 0}}))
+			Expect(theme.Accent).To(Equal(&color{0, 0, 0xff}))
+			Expect(theme.Admin).To(Equal(credentials{"root", "toor"}))
+			Expect(theme.Guests).To(Equal(map[string]credentials{"alice": {"alice", "wonderland"}}))
+		})
+
+	})
+
+	It("should report the errors", func() {
+
+		TestConfigure("theme", &theme_config{})
+		config.TestMap(map[string]string{
+			"theme.background": "blue",
+			"theme.admin":      "root:toor",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*theme_config) {})
+		Expect(err).To(MatchError(ContainSubstring("Can not unmarshal 'blue'")))
+
+	})
+
+})
@@ -0,0 +1,224 @@
+package smartconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by the configured structs which check their own
+// values. The method Validate is called after the configuration of the struct.
+type Validator interface {
+	Validate() error
+}
+
+var (
+	validator_type = reflect.TypeOf((*Validator)(nil)).Elem()
+	duration_type  = reflect.TypeOf(time.Duration(0))
+	bytesize_type  = reflect.TypeOf(ByteSize(0))
+)
+
+// rule checks a configured value, and returns a violation message or an empty
+// string.
+type rule func(reflect.Value) string
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasLength(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(value reflect.Value) float64 {
+	switch {
+	case value.CanInt():
+		return float64(value.Int())
+	case value.CanUint():
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
+
+// parseBound parses the bound of a min or max rule, with the unit of the type
+// for durations and byte sizes.
+func parseBound(typ reflect.Type, bound string) (float64, error) {
+	switch typ {
+	case duration_type:
+		d, err := time.ParseDuration(bound)
+		return float64(d), err
+	case bytesize_type:
+		s, err := ParseByteSize(bound)
+		return float64(s), err
+	default:
+		return strconv.ParseFloat(bound, 64)
+	}
+}
+
+// boundRule returns a rule comparing a number, or the length of a string, a
+// slice or a map, with the bound.
+func boundRule(typ reflect.Type, name, bound string, ok func(a, b float64) bool, msg string) (rule, error) {
+
+	if hasLength(typ.Kind()) {
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag '%s:\"%s\"': %w", name, bound, err)
+		}
+		return func(value reflect.Value) string {
+			if !ok(float64(value.Len()), float64(n)) {
+				return fmt.Sprintf("should have a length %s %d (got %d)", msg, n, value.Len())
+			}
+			return ""
+		}, nil
+	}
+
+	if isNumber(typ.Kind()) {
+		b, err := parseBound(typ, bound)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag '%s:\"%s\"': %w", name, bound, err)
+		}
+		return func(value reflect.Value) string {
+			if !ok(toFloat(value), b) {
+				return fmt.Sprintf("should be %s %s (got %v)", msg, bound, value.Interface())
+			}
+			return ""
+		}, nil
+	}
+
+	return nil, fmt.Errorf("The tag '%s' can not be used for the type %v.", name, typ)
+
+}
+
+// fieldRules returns the validation rules defined by the tags of the field.
+func fieldRules(field reflect.StructField) ([]rule, error) {
+
+	typ := field.Type
+	rules := make([]rule, 0)
+
+	if bound, p := field.Tag.Lookup("min"); p {
+		if r, err := boundRule(typ, "min", bound, func(a, b float64) bool { return a >= b }, "at least"); err != nil {
+			return nil, err
+		} else {
+			rules = append(rules, r)
+		}
+	}
+
+	if bound, p := field.Tag.Lookup("max"); p {
+		if r, err := boundRule(typ, "max", bound, func(a, b float64) bool { return a <= b }, "at most"); err != nil {
+			return nil, err
+		} else {
+			rules = append(rules, r)
+		}
+	}
+
+	if length, p := field.Tag.Lookup("len"); p {
+		if !hasLength(typ.Kind()) {
+			return nil, fmt.Errorf("The tag 'len' can not be used for the type %v.", typ)
+		}
+		if r, err := boundRule(typ, "len", length, func(a, b float64) bool { return a == b }, "of"); err != nil {
+			return nil, err
+		} else {
+			rules = append(rules, r)
+		}
+	}
+
+	if tag, p := field.Tag.Lookup("nonempty"); p {
+		if nonempty, err := strconv.ParseBool(tag); err != nil {
+			return nil, fmt.Errorf("Invalid tag 'nonempty:\"%s\"': %w", tag, err)
+		} else if nonempty {
+			rules = append(rules, func(value reflect.Value) string {
+				if (hasLength(typ.Kind()) && value.Len() == 0) || (!hasLength(typ.Kind()) && value.IsZero()) {
+					return "should not be empty"
+				}
+				return ""
+			})
+		}
+	}
+
+	if tag, p := field.Tag.Lookup("oneof"); p {
+		if !isNumber(typ.Kind()) && typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("The tag 'oneof' can not be used for the type %v.", typ)
+		}
+		allowed := strings.Fields(tag)
+		rules = append(rules, func(value reflect.Value) string {
+			str := fmt.Sprint(value.Interface())
+			for _, a := range allowed {
+				if str == a {
+					return ""
+				}
+			}
+			return fmt.Sprintf("should be one of %v (got '%s')", allowed, str)
+		})
+	}
+
+	if tag, p := field.Tag.Lookup("regexp"); p {
+		if typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("The tag 'regexp' can not be used for the type %v.", typ)
+		}
+		re, err := regexp.Compile(tag)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag 'regexp:\"%s\"': %w", tag, err)
+		}
+		rules = append(rules, func(value reflect.Value) string {
+			if !re.MatchString(value.String()) {
+				return fmt.Sprintf("should match '%s' (got '%s')", tag, value.String())
+			}
+			return ""
+		})
+	}
+
+	return rules, nil
+
+}
+
+// validate checks the value with the rules, and records the violations.
+func validate(rules []rule, path string, value reflect.Value, errs *ConfigurationErrors) {
+	for _, r := range rules {
+		if msg := r(value); msg != "" {
+			errs.add(path, Violation{path, msg})
+		}
+	}
+}
+
+// callValidator calls the method Validate of the configured value, and
+// records its failure as a violation.
+func callValidator(path string, receiver reflect.Value, errs *ConfigurationErrors) {
+
+	ptr, _ := addressable(receiver)
+	if !receiver.CanAddr() {
+		ptr.Elem().Set(receiver)
+	}
+
+	err := ptr.Interface().(Validator).Validate()
+	if err == nil {
+		return
+	}
+
+	var (
+		collected *ConfigurationErrors
+		invalid   *ValidationError
+	)
+	if errors.As(err, &collected) || errors.As(err, &invalid) {
+		errs.add(path, err)
+	} else {
+		errs.add(path, Violation{path, err.Error()})
+	}
+
+}
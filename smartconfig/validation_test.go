@@ -0,0 +1,116 @@
+package smartconfig_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type listener_config struct {
+	Port    int           `min:"1" max:"65535"`
+	Mode    string        `oneof:"http https"`
+	Timeout time.Duration `min:"1s" max:"1m"`
+	Hosts   []string      `nonempty:"true"`
+	Code    string        `len:"3" regexp:"^[A-Z]+$"`
+}
+
+type range_config struct {
+	Low  int
+	High int
+}
+
+func (self range_config) Validate() error {
+	if self.Low > self.High {
+		return fmt.Errorf("low (%d) should not exceed high (%d)", self.Low, self.High)
+	}
+	return nil
+}
+
+type ranges_config struct {
+	Range range_config
+}
+
+type bad_tag_config struct {
+	Enabled bool `min:"1"`
+}
+
+var _ = Describe("Validation", func() {
+
+	It("should accept valid values", func() {
+
+		TestConfigure("listener", &listener_config{})
+		config.TestMap(map[string]string{
+			"listener.port":    "8080",
+			"listener.mode":    "https",
+			"listener.timeout": "10s",
+			"listener.hosts.0": "localhost",
+			"listener.code":    "ABC",
+		})
+
+		ioc.CallInjected(func(l *listener_config) {
+			Expect(l.Port).To(Equal(8080))
+			Expect(l.Code).To(Equal("ABC"))
+		})
+
+	})
+
+	It("should report all the violations with their paths", func() {
+
+		TestConfigure("listener", &listener_config{})
+		config.TestMap(map[string]string{
+			"listener.port":    "0",
+			"listener.mode":    "ftp",
+			"listener.timeout": "2m",
+			"listener.code":    "abcd",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*listener_config) {})
+
+		var invalid *ValidationError
+		Expect(errors.As(err, &invalid)).To(BeTrue())
+
+		paths := make([]string, 0)
+		for _, violation := range invalid.Violations {
+			paths = append(paths, violation.Path)
+		}
+		Expect(paths).To(Equal([]string{
+			"listener.port", "listener.mode", "listener.timeout", "listener.hosts",
+			"listener.code", "listener.code",
+		}))
+
+	})
+
+	It("should call the Validate method", func() {
+
+		TestConfigure("ranges", &ranges_config{})
+		config.TestMap(map[string]string{
+			"ranges.range.low":  "10",
+			"ranges.range.high": "5",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*ranges_config) {})
+
+		var invalid *ValidationError
+		Expect(errors.As(err, &invalid)).To(BeTrue())
+		Expect(invalid.Violations).To(Equal([]Violation{
+			{"ranges.range", "low (10) should not exceed high (5)"},
+		}))
+
+	})
+
+	It("should reject a tag not applicable to the type", func() {
+
+		TestConfigure("bad", &bad_tag_config{})
+
+		err := ioc.ErroneousCallInjected(func(*bad_tag_config) {})
+		Expect(err).To(MatchError(ContainSubstring("The tag 'min' can not be used")))
+
+	})
+
+})
@@ -0,0 +1,201 @@
+package smartconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/b-charles/pigs/ioc"
+)
+
+// DISCRIMINATOR is the key selecting the variant of an interface.
+var DISCRIMINATOR = "type"
+
+// Variant is a concrete type of an interface, selected when the discriminator
+// key of the configuration has the name of the variant.
+type Variant struct {
+	Interface reflect.Type
+	Name      string
+	Concrete  reflect.Type
+}
+
+// NewVariant returns the variant of the interface I with the type of the
+// prototype (e.g. '&S3Storage{}' for a *S3Storage).
+func NewVariant[I any](name string, prototype I) *Variant {
+
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("The type %v is not an interface.", iface))
+	}
+
+	concrete := reflect.TypeOf(prototype)
+	if concrete == nil {
+		panic(fmt.Sprintf("The prototype of the variant '%s' of %v is nil.", name, iface))
+	}
+
+	return &Variant{iface, name, concrete}
+
+}
+
+func (self *Variant) String() string {
+	return fmt.Sprintf("%v variant '%s' (%v)", self.Interface, self.Name, self.Concrete)
+}
+
+func PutVariant[I any](name string, prototype I) {
+	variant := NewVariant(name, prototype)
+	ioc.PutNamed(variant.String(), variant)
+}
+
+func TestPutVariant[I any](name string, prototype I) {
+	variant := NewVariant(name, prototype)
+	ioc.TestPutNamed(variant.String(), variant)
+}
+
+// indexVariants indexes the variants by interface and by name.
+func indexVariants(variants []*Variant) (map[reflect.Type]map[string]*Variant, error) {
+
+	index := make(map[reflect.Type]map[string]*Variant)
+	for _, variant := range variants {
+
+		byName, ok := index[variant.Interface]
+		if !ok {
+			byName = make(map[string]*Variant)
+			index[variant.Interface] = byName
+		}
+
+		if old, ok := byName[variant.Name]; ok {
+			return nil, fmt.Errorf("Two variants '%v' and '%v' are defined with the same name.", old, variant)
+		}
+		byName[variant.Name] = variant
+
+	}
+
+	return index, nil
+
+}
+
+func variantNames(variants map[string]*Variant) []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hiddenKeyNavConfig is a node without one of its children, used to hide the
+// discriminator key to the strict structs.
+type hiddenKeyNavConfig struct {
+	NavConfig
+	hidden string
+}
+
+func (self *hiddenKeyNavConfig) Keys() []string {
+	keys := make([]string, 0)
+	for _, key := range self.NavConfig.Keys() {
+		if key != self.hidden {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// checkDiscriminator returns an error if a key of the variant is the
+// discriminator, which would be hidden to the variant.
+func checkDiscriminator(variant *Variant, recfun func(reflect.Type) (*configurer, error)) error {
+
+	concrete := variant.Concrete
+	for concrete.Kind() == reflect.Pointer {
+		concrete = concrete.Elem()
+	}
+
+	configurer, err := recfun(concrete)
+	if err != nil || configurer.keys == nil {
+		return err
+	}
+
+	keys, err := configurer.keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key == DISCRIMINATOR || strings.HasPrefix(key, DISCRIMINATOR+".") {
+			return fmt.Errorf("The key '%s' of the variant '%v' collides with the discriminator.", key, variant)
+		}
+	}
+
+	return nil
+
+}
+
+// Interface configurer
+
+func newInterfaceConfigurer(target reflect.Type, variants map[string]*Variant, recfun func(reflect.Type) (*configurer, error)) (*configurer, error) {
+
+	if target.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("The target '%v' is not an interface.", target)
+	}
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("No variant defined for the interface %v.", target)
+	}
+
+	for _, variant := range variants {
+		if !variant.Concrete.Implements(target) {
+			return nil, fmt.Errorf("The type of the variant '%v' doesn't implement %v.", variant, target)
+		}
+	}
+
+	return &configurer{
+		target: target,
+		setter: func(config NavConfig, receiver reflect.Value) error {
+
+			typ := receiver.Type()
+			if typ != target {
+				return fmt.Errorf("Unexpected value '%v' (%v): not a %v.", receiver, typ, target)
+			}
+
+			if isMissing(config) {
+				return nil
+			}
+
+			discriminator := config.Get(DISCRIMINATOR)
+			if isMissing(discriminator) {
+				return fmt.Errorf("No variant of %v selected: the key '%s' is not defined, expected one of %v.",
+					target, path(config.Path(), DISCRIMINATOR), variantNames(variants))
+			}
+
+			name := discriminator.Value()
+			variant, ok := variants[name]
+			if !ok {
+				return fmt.Errorf("Unknown variant '%s' of %v for '%s', expected one of %v.",
+					name, target, path(config.Path(), DISCRIMINATOR), variantNames(variants))
+			}
+
+			configurer, err := recfun(variant.Concrete)
+			if err != nil {
+				return fmt.Errorf("Can not configure the variant '%v': %w", variant, err)
+			}
+			if err := checkDiscriminator(variant, recfun); err != nil {
+				return err
+			}
+
+			if s, ok := config.(*strictNavConfig); ok {
+				config = strict(&hiddenKeyNavConfig{s.NavConfig, DISCRIMINATOR})
+			} else {
+				config = &hiddenKeyNavConfig{config, DISCRIMINATOR}
+			}
+
+			value := reflect.New(variant.Concrete).Elem()
+			if err := configurer.setter(config, value); err != nil {
+				return err
+			}
+
+			receiver.Set(value)
+			return nil
+
+		},
+	}, nil
+
+}
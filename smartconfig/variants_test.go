@@ -0,0 +1,149 @@
+package smartconfig_test
+
+import (
+	"errors"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type storage interface {
+	Location() string
+}
+
+type s3_storage struct {
+	Bucket string
+	Region string
+}
+
+func (self *s3_storage) Location() string {
+	return "s3://" + self.Bucket
+}
+
+type disk_storage struct {
+	_    struct{} `config:",strict"`
+	Path string
+}
+
+func (self disk_storage) Location() string {
+	return "file://" + self.Path
+}
+
+type ftp_storage struct {
+	Host string
+	Type string
+}
+
+func (self ftp_storage) Location() string {
+	return "ftp://" + self.Host
+}
+
+type storages_config struct {
+	Primary storage
+	Backups []storage
+}
+
+var _ = Describe("Variants", func() {
+
+	BeforeEach(func() {
+		TestPutVariant[storage]("s3", &s3_storage{})
+		TestPutVariant[storage]("disk", disk_storage{})
+	})
+
+	It("should select the variant with the discriminator", func() {
+
+		TestConfigure("storages", &storages_config{})
+		config.TestMap(map[string]string{
+			"storages.primary.type":   "s3",
+			"storages.primary.bucket": "data",
+			"storages.primary.region": "eu-west-3",
+			"storages.backups.0.type": "disk",
+			"storages.backups.0.path": "/backup",
+		})
+
+		ioc.CallInjected(func(injected *storages_config) {
+			Expect(injected).To(Equal(&storages_config{
+				Primary: &s3_storage{Bucket: "data", Region: "eu-west-3"},
+				Backups: []storage{disk_storage{Path: "/backup"}},
+			}))
+		})
+
+	})
+
+	It("should leave an undefined interface nil", func() {
+
+		TestConfigure("storages", &storages_config{})
+		config.Test("storages.backups.0.type", "disk")
+
+		ioc.CallInjected(func(injected *storages_config) {
+			Expect(injected.Primary).To(BeNil())
+			Expect(injected.Backups).To(HaveLen(1))
+		})
+
+	})
+
+	It("should reject an unknown variant", func() {
+
+		TestConfigure("storages", &storages_config{})
+		config.TestMap(map[string]string{
+			"storages.primary.type":   "ftp",
+			"storages.primary.bucket": "data",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*storages_config) {})
+
+		var errs *ConfigurationErrors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs.Paths()).To(Equal([]string{"storages.primary"}))
+		Expect(err).To(MatchError(ContainSubstring("Unknown variant 'ftp'")))
+
+	})
+
+	It("should reject a missing discriminator", func() {
+
+		TestConfigure("storages", &storages_config{})
+		config.TestMap(map[string]string{
+			"storages.primary.bucket": "data",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*storages_config) {})
+		Expect(err).To(MatchError(ContainSubstring("the key 'storages.primary.type' is not defined")))
+
+	})
+
+	It("should reject a variant with the key of the discriminator", func() {
+
+		TestPutVariant[storage]("ftp", ftp_storage{})
+		TestConfigure("storages", &storages_config{})
+		config.TestMap(map[string]string{
+			"storages.primary.type": "ftp",
+			"storages.primary.host": "ftp.local",
+		})
+
+		err := ioc.ErroneousCallInjected(func(*storages_config) {})
+		Expect(err).To(MatchError(ContainSubstring("The key 'type' of the variant")))
+
+	})
+
+	It("should describe the variants in the Json Schema", func() {
+
+		config.TestMap(map[string]string{})
+
+		ioc.CallInjected(func(configurer *SmartConfigurer) {
+
+			schema, err := configurer.JsonSchemaOf(&storages_config{})
+			Expect(err).To(Succeed())
+
+			oneOf := schema.GetMember("properties").GetMember("primary").GetMember("oneOf")
+			Expect(oneOf.GetLen()).To(Equal(2))
+			Expect(oneOf.GetElement(0).GetMember("properties").GetMember("type").GetMember("const").AsString()).To(Equal("disk"))
+			Expect(oneOf.GetElement(1).GetMember("properties").GetMember("type").GetMember("const").AsString()).To(Equal("s3"))
+
+		})
+
+	})
+
+})
@@ -0,0 +1,163 @@
+package smartconfig
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+)
+
+// Watched holds a configured value, configured again each time the
+// configuration changes. The value is swapped atomically: Load always returns
+// a complete value, the previous one or the new one. If the new configuration
+// can not be configured, the previous value is kept.
+type Watched[T any] struct {
+	configurer *SmartConfigurer
+	root       string
+	opts       []Option
+	value      atomic.Pointer[T]
+	mu         sync.Mutex
+	err        error
+	listeners  []func(old, new T)
+}
+
+func newWatched[T any](configurer *SmartConfigurer, root string, opts []Option) (*Watched[T], error) {
+
+	watched := &Watched[T]{
+		configurer: configurer,
+		root:       root,
+		opts:       opts,
+		listeners:  []func(T, T){},
+	}
+
+	var value T
+	if err := configurer.Configure(root, &value, opts...); err != nil {
+		return nil, err
+	}
+	watched.value.Store(&value)
+
+	return watched, nil
+
+}
+
+// Load returns the current value.
+func (self *Watched[T]) Load() T {
+	return *self.value.Load()
+}
+
+// OnChange records a listener, called with the previous and the new value
+// each time the value changes.
+func (self *Watched[T]) OnChange(listener func(old, new T)) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.listeners = append(self.listeners, listener)
+}
+
+// LastError returns the error of the last reload, or nil if it has succeeded.
+func (self *Watched[T]) LastError() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.err
+}
+
+// Reload configures a new value from the given configuration tree. If the new
+// value differs from the current one, it replaces it and the listeners are
+// notified. The listeners are called without holding the lock of the watched
+// value, so they can use it.
+func (self *Watched[T]) Reload(config NavConfig) error {
+
+	self.mu.Lock()
+
+	var value T
+	configurer := &SmartConfigurer{config: config, configurers: self.configurer.configurers, variants: self.configurer.variants}
+	bind(config, configurer)
+	if err := configurer.Configure(self.root, &value, self.opts...); err != nil {
+		self.err = err
+		self.mu.Unlock()
+		return err
+	}
+	self.err = nil
+
+	old := self.Load()
+	if reflect.DeepEqual(old, value) {
+		self.mu.Unlock()
+		return nil
+	}
+
+	self.value.Store(&value)
+	listeners := make([]func(T, T), len(self.listeners))
+	copy(listeners, self.listeners)
+
+	self.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(old, value)
+	}
+
+	return nil
+
+}
+
+// createWatched returns a factory of a Watched value, reloaded each time the
+// remote configuration source detects a change. The configuration is rebuilt
+// from all the sources, so the keys removed from the remote backend are
+// removed and the merge strategies are applied.
+func createWatched[T any](root string, opts []Option) any {
+	return func(configurer *SmartConfigurer, remote config.RemoteConfigSource,
+		sources []config.ConfigSource, resolvers []config.PlaceholderResolver,
+		schema *config.Schema) (*Watched[T], error) {
+
+		watched, err := newWatched[T](configurer, root, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		remote.OnChange(func(map[string]string) {
+			configuration, err := config.CreateConfiguration(sources, resolvers, schema)
+			var nav NavConfig
+			if err == nil {
+				nav, err = NewNavMap(configuration)
+			}
+			if err != nil {
+				watched.mu.Lock()
+				watched.err = err
+				watched.mu.Unlock()
+			} else {
+				watched.Reload(nav)
+			}
+		})
+
+		return watched, nil
+
+	}
+}
+
+func DefaultWatch[T any](root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.DefaultPutFactory(createWatched[T](root, opts))
+}
+
+func DefaultWatchNamed[T any](name string, root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.DefaultPutNamedFactory(name, createWatched[T](root, opts))
+}
+
+func Watch[T any](root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.PutFactory(createWatched[T](root, opts))
+}
+
+func WatchNamed[T any](name string, root string, opts ...Option) {
+	recordRoot(root, reflect.TypeOf((*T)(nil)).Elem(), opts)
+	ioc.PutNamedFactory(name, createWatched[T](root, opts))
+}
+
+func TestWatch[T any](root string, opts ...Option) {
+	ioc.TestPutFactory(createWatched[T](root, opts))
+}
+
+func TestWatchNamed[T any](name string, root string, opts ...Option) {
+	ioc.TestPutNamedFactory(name, createWatched[T](root, opts))
+}
@@ -0,0 +1,105 @@
+package smartconfig_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	"github.com/b-charles/pigs/config"
+	"github.com/b-charles/pigs/ioc"
+	. "github.com/b-charles/pigs/smartconfig"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+type limiter_config struct {
+	Rate  int `min:"1"`
+	Burst int
+}
+
+var _ = Describe("Watched", func() {
+
+	It("should reload the value", func() {
+
+		TestWatch[limiter_config]("limiter")
+		config.TestMap(map[string]string{
+			"limiter.rate":  "10",
+			"limiter.burst": "20",
+		})
+
+		ioc.CallInjected(func(watched *Watched[limiter_config], configuration config.Configuration) {
+
+			Expect(watched.Load()).To(Equal(limiter_config{10, 20}))
+
+			changes := 0
+			watched.OnChange(func(old, new limiter_config) {
+				Expect(old).To(Equal(limiter_config{10, 20}))
+				Expect(new).To(Equal(limiter_config{5, 20}))
+				Expect(watched.LastError()).To(Succeed())
+				watched.OnChange(func(limiter_config, limiter_config) {})
+				changes++
+			})
+
+			overridden, _ := configuration.WithOverrides(map[string]string{"limiter.rate": "5"})
+			nav, _ := NewNavMap(overridden)
+			Expect(watched.Reload(nav)).To(Succeed())
+			Expect(watched.Load()).To(Equal(limiter_config{5, 20}))
+
+			Expect(watched.Reload(nav)).To(Succeed())
+			Expect(changes).To(Equal(1))
+
+			overridden, _ = configuration.WithOverrides(map[string]string{"limiter.rate": "0"})
+			nav, _ = NewNavMap(overridden)
+			Expect(watched.Reload(nav)).NotTo(Succeed())
+			Expect(watched.LastError()).To(HaveOccurred())
+			Expect(watched.Load()).To(Equal(limiter_config{5, 20}))
+			Expect(changes).To(Equal(1))
+
+		})
+
+	})
+
+	It("should reload the value when the remote configuration changes", func() {
+
+		var body atomic.Value
+		body.Store(`{"limiter":{"rate":"10","burst":"20"}}`)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body.Load().(string)))
+		}))
+		defer server.Close()
+
+		backup := config.BackupDefault()
+		defer config.RestoreDefault(backup)
+		config.Set(config.CONFIG_SOURCE_REMOTE_URL, server.URL)
+		ioc.TestPut(afero.NewMemMapFs(), func(afero.Fs) {})
+
+		TestWatch[*limiter_config]("limiter")
+
+		ioc.CallInjected(func(watched *Watched[*limiter_config], remote *config.RemoteConfigSourceImpl) {
+
+			Expect(watched.Load()).To(Equal(&limiter_config{10, 20}))
+
+			body.Store(`{"limiter":{"rate":"100","burst":"200"}}`)
+			Expect(remote.Refresh()).To(Succeed())
+
+			Expect(watched.Load()).To(Equal(&limiter_config{100, 200}))
+			Expect(watched.LastError()).To(Succeed())
+
+			body.Store(`{"limiter":{"rate":"100"}}`)
+			Expect(remote.Refresh()).To(Succeed())
+
+			Expect(watched.Load()).To(Equal(&limiter_config{100, 200}))
+			Expect(watched.LastError()).To(MatchError(ContainSubstring("limiter.burst")))
+
+			body.Store(`{"limiter":{"rate":"${missing}","burst":"200"},"config.placeholders.strict":"true"}`)
+			Expect(remote.Refresh()).To(Succeed())
+
+			Expect(watched.Load()).To(Equal(&limiter_config{100, 200}))
+			Expect(watched.LastError()).To(MatchError(ContainSubstring("Can not resolve the key 'limiter.rate'")))
+
+		})
+
+	})
+
+})